@@ -0,0 +1,84 @@
+package api
+
+import (
+	"time"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+type TypedCid struct {
+	Type string `json:"type"`
+	Cid  string `json:"cid"`
+	// declared by the uploader, not verified
+	Size int64 `json:"size,omitempty"`
+}
+
+type Retraction struct {
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// Signer is the author of a document revision. VerifyKey is the raw ed25519 key in base64.
+type Signer struct {
+	VerifyKey   []byte `json:"verifyKey"`
+	Fingerprint string `json:"fingerprint"`
+	Valid       bool   `json:"valid"`
+}
+
+// Document is the JSON form of a document.
+type Document struct {
+	Key         string      `json:"key"`
+	Title       string      `json:"title"`
+	Time        time.Time   `json:"time"`
+	Types       []string    `json:"types"`
+	Tags        []string    `json:"tags"`
+	Description string      `json:"description"`
+	Revision    int64       `json:"revision"`
+	Prev        string      `json:"prev,omitempty"`
+	Cids        []TypedCid  `json:"cids"`
+	Retraction  *Retraction `json:"retraction,omitempty"`
+	Signer      *Signer     `json:"signer,omitempty"`
+}
+
+func NewDocument(nd *store.NamedDocument) *Document {
+	cids := make([]TypedCid, len(nd.Cids))
+	for idx, tc := range nd.Cids {
+		cids[idx] = TypedCid{tc.Type, tc.Cid, tc.Size}
+	}
+	var r *Retraction
+	if nd.Retraction != nil {
+		r = &Retraction{nd.Retraction.Reason, nd.Retraction.Time}
+	}
+	var sgn *Signer
+	if nd.Signer != nil {
+		sgn = &Signer{nd.Signer.Verify, nd.Signer.Fingerprint, nd.Signer.Valid}
+	}
+	return &Document{
+		Key:         nd.Name,
+		Title:       nd.Title,
+		Time:        nd.Time,
+		Types:       nd.DocTypes,
+		Tags:        nd.Tags,
+		Description: nd.Description,
+		Revision:    nd.Revision,
+		Prev:        nd.Prev,
+		Cids:        cids,
+		Retraction:  r,
+		Signer:      sgn,
+	}
+}
+
+// Report is the JSON form of an archive report.
+type Report struct {
+	Entries int      `json:"entries"`
+	Data    int      `json:"data"`
+	Failed  []string `json:"failed"`
+}
+
+func NewReport(r *store.ArchiveReport) *Report {
+	failed := r.Failed
+	if failed == nil {
+		failed = make([]string, 0)
+	}
+	return &Report{r.Entries, r.Data, failed}
+}
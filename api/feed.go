@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+// the number of entries of a feed without limit
+const defaultFeedLimit = 50
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+	Link       *atomLink      `xml:"link,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// escapeKey escapes each part of a document key, since names and titles may have spaces.
+func escapeKey(key string) string {
+	keys := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for idx, k := range keys {
+		keys[idx] = url.PathEscape(k)
+	}
+	return strings.Join(keys, "/")
+}
+
+// Feed makes an Atom feed of the documents of the query.
+// link is the base url of the document pages (e.g. a gateway), "" for no links.
+// Ids are made from the StoreKey, which stays the same when the bootstrap addresses change.
+func Feed(st store.IDocumentStore, qp *QueryParams, title, link string) ([]byte, error) {
+	if qp.Limit <= 0 {
+		qp.Limit = defaultFeedLimit
+	}
+	stKey, err := store.StoreKey(st.Address())
+	if err != nil {
+		return nil, err
+	}
+	docs, err := qp.Documents(st)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &atomFeed{
+		ID:      "urn:lontan:" + stKey + "?" + qp.values().Encode(),
+		Title:   title,
+		Entries: make([]atomEntry, len(docs)),
+	}
+	updated := time.Unix(0, 0)
+	if link != "" {
+		feed.Link = &atomLink{Href: link, Rel: "alternate"}
+	}
+	for idx, doc := range docs {
+		author := ""
+		if keys := strings.Split(doc.Key, "/"); len(keys) == 3 {
+			author = keys[1]
+		}
+		entry := atomEntry{
+			ID:         "urn:lontan:" + stKey + "/" + escapeKey(doc.Key),
+			Title:      doc.Title,
+			Updated:    atomTime(doc.Time),
+			Author:     atomAuthor{author},
+			Summary:    doc.Description,
+			Categories: make([]atomCategory, len(doc.Tags)),
+		}
+		for tIdx, tag := range doc.Tags {
+			entry.Categories[tIdx] = atomCategory{tag}
+		}
+		if link != "" {
+			entry.Link = &atomLink{Href: strings.TrimSuffix(link, "/") + "/doc/" + escapeKey(doc.Key), Rel: "alternate"}
+		}
+		feed.Entries[idx] = entry
+
+		if doc.Time.After(updated) {
+			updated = doc.Time
+		}
+	}
+	feed.Updated = atomTime(updated)
+
+	m, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), m...), nil
+}
+
+// values is the inverse of QueryParamsFromValues.
+func (qp *QueryParams) values() url.Values {
+	v := url.Values{}
+	set := func(key, val string) {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	set("q", qp.Expr)
+	v["tag"] = qp.Tags
+	v["type"] = qp.Types
+	v["cid"] = qp.Cids
+	set("author", qp.Author)
+	set("title", qp.Title)
+	set("after", qp.After)
+	set("before", qp.Before)
+	set("text", qp.Text)
+	set("order", qp.Order)
+	if qp.Retracted {
+		v.Set("retracted", "true")
+	}
+	return v
+}
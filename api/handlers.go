@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+func (s *Server) searchDocuments(w http.ResponseWriter, r *http.Request, st store.IDocumentStore) {
+	qp, err := QueryParamsFromValues(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	docs, err := qp.Documents(st)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, docs)
+}
+
+// ServeFeed writes the Atom feed of the search in the url query.
+func ServeFeed(w http.ResponseWriter, r *http.Request, st store.IDocumentStore, title, link string) {
+	qp, err := QueryParamsFromValues(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := Feed(st, qp, title, link)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(m)
+}
+
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request, st store.IDocumentStore) {
+	ServeFeed(w, r, st, st.Address(), r.URL.Query().Get("link"))
+}
+
+func (s *Server) getDocument(w http.ResponseWriter, r *http.Request, st store.IDocumentStore, key string) {
+	var nd *store.NamedDocument
+	var err error
+	if rev := r.URL.Query().Get("revision"); rev != "" {
+		n, perr := strconv.ParseInt(rev, 10, 64)
+		if perr != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid revision"))
+			return
+		}
+		nd, err = st.GetRevision(key, n)
+	} else {
+		nd, err = st.Get(key)
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, NewDocument(nd))
+}
+
+// GetMedia fetches and decodes the media of the cid.
+// Only cids referenced by a document of st are served, decoded by the type the document records.
+func GetMedia(st store.IDocumentStore, cid string, page int, audio bool) (*Media, error) {
+	tp, err := st.CidType(cid)
+	if err != nil {
+		return nil, err
+	}
+	m, err := st.Ipfs().Get(cid, apiFetchTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeMedia(tp, m, page, audio)
+}
+
+// ServeMedia writes the media with range support, so that players can seek.
+func ServeMedia(w http.ResponseWriter, r *http.Request, media *Media) {
+	w.Header().Set("Content-Type", media.ContentType)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(media.Data))
+}
+
+func (s *Server) getMedia(w http.ResponseWriter, r *http.Request, st store.IDocumentStore, cid string) {
+	v := r.URL.Query()
+	page := 0
+	if p := v.Get("page"); p != "" {
+		var err error
+		if page, err = strconv.Atoi(p); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid page"))
+			return
+		}
+	}
+	media, err := GetMedia(st, cid, page, v.Get("audio") == "true")
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	ServeMedia(w, r, media)
+}
+
+type publishFile struct {
+	// the extension of the name detects the type
+	Name string `json:"name"`
+	Data []byte `json:"data"`
+}
+
+// publishRequest is the body to publish a document.
+// The identity is taken from Keystore, a keystore file or a file of store.ExportIdentity, opened with Passphrase.
+// With Passphrase only, the keystore next to the binary is used. User is the identity name in it
+// (default: the default identity of the store, or the only one).
+// Identity is a user identity string of older versions.
+// Without identity, the document is published anonymously.
+type publishRequest struct {
+	Keystore    []byte        `json:"keystore"`
+	Passphrase  string        `json:"passphrase"`
+	User        string        `json:"user"`
+	Identity    string        `json:"identity"`
+	Name        string        `json:"name"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Tags        []string      `json:"tags"`
+	Texts       []string      `json:"texts"`
+	Files       []publishFile `json:"files"`
+}
+
+// encodeUpload encodes the file through a temporary file, as the encoders read local files.
+func encodeUpload(pf publishFile) (*store.TypedData, error) {
+	f, err := os.CreateTemp("", "lontan_upload_*"+filepath.Ext(pf.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(pf.Data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return store.EncodeFile(f.Name())
+}
+
+func sliceToMap(slc []string) map[string]struct{} {
+	mp := make(map[string]struct{}, len(slc))
+	for _, elem := range slc {
+		mp[elem] = struct{}{}
+	}
+	return mp
+}
+
+func (req *publishRequest) userIdentity(addr string) (*store.UserIdentity, error) {
+	if req.Passphrase != "" {
+		m := req.Keystore
+		if len(m) == 0 {
+			var err error
+			if m, err = os.ReadFile(store.KeystorePath()); err != nil {
+				return nil, err
+			}
+		}
+		return store.KeystoreIdentity(m, req.Passphrase, req.User, addr)
+	}
+	if len(req.Keystore) > 0 {
+		return nil, errors.New("no passphrase of the keystore")
+	}
+	ui := store.AnonymousIdentity()
+	if req.Identity != "" {
+		if err := ui.FromString(req.Identity); err != nil {
+			return nil, errors.New("invalid user identity")
+		}
+	}
+	return ui, nil
+}
+
+func (s *Server) publishDocument(w http.ResponseWriter, r *http.Request, st store.IDocumentStore) {
+	req := &publishRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<30)).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Name == "" || req.Title == "" || req.Description == "" || strings.Contains(req.Name, "/") {
+		writeError(w, http.StatusBadRequest, errors.New("invalid name, title or description"))
+		return
+	}
+	ui, err := req.userIdentity(st.Address())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tds := make([]*store.TypedData, 0)
+	docTypes := make([]string, 0)
+	for _, text := range req.Texts {
+		tds = append(tds, store.NewTypedData("text", bytes.NewBufferString(text)))
+		docTypes = append(docTypes, "text")
+	}
+	for _, pf := range req.Files {
+		td, err := encodeUpload(pf)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		tds = append(tds, td)
+		docTypes = append(docTypes, td.Type())
+	}
+	if len(tds) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no data"))
+		return
+	}
+
+	docInfo := store.NewDocumentInfo(req.Title, req.Description, sliceToMap(docTypes), sliceToMap(req.Tags), time.Now().UTC())
+	if err := st.PutAs(ui, req.Name, docInfo, tds...); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	nd, err := st.Get(ui.Pid() + "/" + ui.UserName() + "/" + req.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, NewDocument(nd))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+// Media is the decoded data of a cid, ready to be served.
+type Media struct {
+	ContentType string
+	Data        []byte
+}
+
+// DecodeMedia decodes the data of a cid by its type.
+// page selects the page of a pdf.
+// A video has its sound in a separate track, selected by audio.
+func DecodeMedia(tp string, m []byte, page int, audio bool) (*Media, error) {
+	switch tp {
+	case "text":
+		return &Media{"text/plain; charset=utf-8", m}, nil
+	case "image":
+		img := &pb.Image{}
+		if err := proto.Unmarshal(m, img); err != nil {
+			return nil, err
+		}
+		return &Media{"image/webp", img.GetData()}, nil
+	case "pdf":
+		pdf := &pb.Pdf{}
+		if err := proto.Unmarshal(m, pdf); err != nil {
+			return nil, err
+		}
+		if page < 0 || page >= len(pdf.GetImages()) {
+			return nil, errors.New("page out of range")
+		}
+		return &Media{"image/webp", pdf.GetImages()[page]}, nil
+	case "video":
+		video := &pb.Video{}
+		if err := proto.Unmarshal(m, video); err != nil {
+			return nil, err
+		}
+		if audio {
+			return &Media{"audio/mpeg", video.GetAudio()}, nil
+		}
+		return &Media{http.DetectContentType(video.GetVideo()), video.GetVideo()}, nil
+	case "audio":
+		a := &pb.Audio{}
+		if err := proto.Unmarshal(m, a); err != nil {
+			return nil, err
+		}
+		return &Media{"audio/mpeg", a.GetData()}, nil
+	default:
+		return nil, errors.New("unknown data type " + tp)
+	}
+}
+
+// PdfPages returns the number of pages of a pdf.
+func PdfPages(m []byte) (int, error) {
+	pdf := &pb.Pdf{}
+	if err := proto.Unmarshal(m, pdf); err != nil {
+		return 0, err
+	}
+	return len(pdf.GetImages()), nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+// QueryParams are the search conditions shared by the CLI and the HTTP endpoints.
+// Dates are 2006-01-02.
+type QueryParams struct {
+	Expr      string
+	Tags      []string
+	Types     []string
+	Cids      []string
+	Author    string
+	Title     string
+	After     string
+	Before    string
+	Text      string
+	Order     string
+	Offset    int
+	Limit     int
+	Retracted bool
+}
+
+// QueryParamsFromValues reads the url query: q, tag, type, cid, author, title,
+// after, before, text, order (newer or older), offset, limit and retracted.
+func QueryParamsFromValues(v url.Values) (*QueryParams, error) {
+	qp := &QueryParams{
+		Expr:      v.Get("q"),
+		Tags:      v["tag"],
+		Types:     v["type"],
+		Cids:      v["cid"],
+		Author:    v.Get("author"),
+		Title:     v.Get("title"),
+		After:     v.Get("after"),
+		Before:    v.Get("before"),
+		Text:      v.Get("text"),
+		Order:     v.Get("order"),
+		Retracted: v.Get("retracted") == "true",
+	}
+	var err error
+	if s := v.Get("offset"); s != "" {
+		if qp.Offset, err = strconv.Atoi(s); err != nil {
+			return nil, errors.New("invalid offset")
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if qp.Limit, err = strconv.Atoi(s); err != nil {
+			return nil, errors.New("invalid limit")
+		}
+	}
+	return qp, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", s)
+}
+
+// Query makes the store query. The full text relevance needs the store.
+func (qp *QueryParams) Query(st store.IDocumentStore) (query.Query, error) {
+	q := query.Query{Offset: qp.Offset, Limit: qp.Limit}
+	if qp.Expr != "" {
+		f, err := store.ParseQuery(qp.Expr)
+		if err != nil {
+			return query.Query{}, err
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	if len(qp.Tags) > 0 {
+		q.Filters = append(q.Filters, store.TagsFilter{Tags: qp.Tags})
+	}
+	if len(qp.Types) > 0 {
+		q.Filters = append(q.Filters, store.DocTypesFilter{DocTypes: qp.Types})
+	}
+	if len(qp.Cids) > 0 {
+		q.Filters = append(q.Filters, store.CidsFilter{Cids: qp.Cids})
+	}
+	if qp.Author != "" {
+		q.Filters = append(q.Filters, store.AuthorFilter{Author: qp.Author})
+	}
+	if qp.Title != "" {
+		q.Filters = append(q.Filters, store.TitleFilter{Title: qp.Title})
+	}
+	if qp.After != "" || qp.Before != "" {
+		tf := store.TimeFilter{End: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)}
+		var err error
+		if qp.After != "" {
+			if tf.Begin, err = parseDate(qp.After); err != nil {
+				return query.Query{}, errors.New("invalid after")
+			}
+		}
+		if qp.Before != "" {
+			if tf.End, err = parseDate(qp.Before); err != nil {
+				return query.Query{}, errors.New("invalid before")
+			}
+		}
+		q.Filters = append(q.Filters, tf)
+	}
+	if qp.Retracted {
+		q.Filters = append(q.Filters, store.IncludeRetracted{})
+	}
+
+	switch qp.Order {
+	case "", "newer":
+		q.Orders = []query.Order{store.TimeOrder{FrontNew: true}}
+	case "older":
+		q.Orders = []query.Order{store.TimeOrder{FrontNew: false}}
+	default:
+		return query.Query{}, errors.New("invalid order " + qp.Order)
+	}
+	if qp.Text != "" {
+		o := st.Relevance(qp.Text)
+		q.Filters = append(q.Filters, o)
+		q.Orders = []query.Order{o}
+	}
+	return q, nil
+}
+
+// Documents runs the query.
+func (qp *QueryParams) Documents(st store.IDocumentStore) ([]*Document, error) {
+	q, err := qp.Query(st)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := st.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*Document, 0)
+	for nd := range ch {
+		docs = append(docs, NewDocument(nd))
+	}
+	return docs, nil
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+const apiFetchTimeout = time.Minute
+
+// LoadToken reads the api token at path, or makes one at the first run.
+// Local scripts read the token from the same file.
+func LoadToken(path string) (string, error) {
+	m, err := os.ReadFile(path)
+	if err == nil && len(m) > 0 {
+		return strings.TrimSpace(string(m)), nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Server is the local HTTP/JSON api of the stores of a StoreManager.
+// It listens on localhost only, and every request needs the token
+// as "Authorization: Bearer <token>" or as the token parameter.
+//
+//	GET  /api/stores                            loaded stores
+//	POST /api/stores                            load a store: {"address"}
+//	GET  /api/stores/<store>/documents          search (see QueryParamsFromValues)
+//	POST /api/stores/<store>/documents          publish (see publishRequest)
+//	GET  /api/stores/<store>/document/<key>     a document, ?revision=n for a revision
+//	GET  /api/stores/<store>/feed               Atom feed of a search, ?link= for the base url of document pages
+//	GET  /api/stores/<store>/media/<cid>        decoded media of a cid in a document, ?page= for a pdf, ?audio=true for the sound of a video
+type Server struct {
+	sm    *store.StoreManager
+	token string
+	srv   *http.Server
+}
+
+func NewServer(sm *store.StoreManager, port int, token string) *Server {
+	s := &Server{sm: sm, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/stores", s.handleStores)
+	mux.HandleFunc("/api/stores/", s.handleStore)
+	s.srv = &http.Server{
+		Addr:    net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		Handler: s.withToken(mux),
+	}
+	return s
+}
+
+// Start serves in the background. It returns when the port is bound.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	go s.srv.Serve(ln)
+	return nil
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) withToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+type storeInfo struct {
+	Key     string `json:"key"`
+	Address string `json:"address"`
+}
+
+func (s *Server) handleStores(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		infos := make([]storeInfo, 0)
+		for _, key := range s.sm.Keys() {
+			if st, ok := s.sm.Get(key); ok {
+				infos = append(infos, storeInfo{key, st.Address()})
+			}
+		}
+		writeJSON(w, infos)
+	case http.MethodPost:
+		req := &struct {
+			Address string `json:"address"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		key, st, err := s.sm.Load(req.Address)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, storeInfo{key, st.Address()})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// handleStore routes /api/stores/<store>/<resource>/<rest>.
+func (s *Server) handleStore(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/api/stores/"), "/", 3)
+	if len(parts) < 2 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	st, ok := s.sm.Get(parts[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("store is not loaded"))
+		return
+	}
+	rest := ""
+	if len(parts) == 3 {
+		rest = parts[2]
+	}
+
+	switch {
+	case parts[1] == "documents" && rest == "" && r.Method == http.MethodGet:
+		s.searchDocuments(w, r, st)
+	case parts[1] == "documents" && rest == "" && r.Method == http.MethodPost:
+		s.publishDocument(w, r, st)
+	case parts[1] == "feed" && rest == "" && r.Method == http.MethodGet:
+		s.getFeed(w, r, st)
+	case parts[1] == "document" && rest != "" && r.Method == http.MethodGet:
+		s.getDocument(w, r, st, rest)
+	case parts[1] == "media" && rest != "" && r.Method == http.MethodGet:
+		s.getMedia(w, r, st, rest)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
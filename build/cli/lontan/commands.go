@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	api "github.com/pilinsin/lontan/api"
+	store "github.com/pilinsin/lontan/store"
+)
+
+func sliceToMap(slc []string) map[string]struct{} {
+	mp := make(map[string]struct{}, len(slc))
+	for _, elem := range slc {
+		mp[elem] = struct{}{}
+	}
+	return mp
+}
+
+func runPut(args []string) error {
+	fs := flag.NewFlagSet("put", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	name := fs.String("name", "", "document name")
+	title := fs.String("title", "", "title")
+	description := fs.String("description", "", "description")
+	tags := &stringsFlag{}
+	fs.Var(tags, "tag", "tag (repeatable)")
+	texts := &stringsFlag{}
+	fs.Var(texts, "text", "text data (repeatable)")
+	fs.Parse(args)
+
+	if *name == "" || *title == "" || *description == "" {
+		return errors.New("-name, -title and -description are required")
+	}
+	tds := make([]*store.TypedData, 0)
+	docTypes := make([]string, 0)
+	for _, text := range *texts {
+		tds = append(tds, store.NewTypedData("text", bytes.NewBufferString(text)))
+		docTypes = append(docTypes, "text")
+	}
+	for _, path := range fs.Args() {
+		td, err := store.EncodeFile(path)
+		if err != nil {
+			return err
+		}
+		tds = append(tds, td)
+		docTypes = append(docTypes, td.Type())
+	}
+	if len(tds) == 0 {
+		return errors.New("no data")
+	}
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	docInfo := store.NewDocumentInfo(*title, *description, sliceToMap(docTypes), sliceToMap(*tags), time.Now().UTC())
+	if err := s.st.Put(*name, docInfo, tds...); err != nil {
+		return err
+	}
+	s.wait()
+	nd, err := s.st.Get(s.ui.Pid() + "/" + s.ui.UserName() + "/" + *name)
+	if err != nil {
+		return err
+	}
+	return printJSON(api.NewDocument(nd))
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	revision := fs.Int64("revision", -1, "revision number (default: the latest)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("get takes one document key")
+	}
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	s.wait()
+
+	var nd *store.NamedDocument
+	if *revision < 0 {
+		nd, err = s.st.Get(fs.Arg(0))
+	} else {
+		nd, err = s.st.GetRevision(fs.Arg(0), *revision)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(api.NewDocument(nd))
+}
+
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	qp := &api.QueryParams{}
+	fs.StringVar(&qp.Expr, "q", "", "query expression, e.g. 'tag:leak -type:video'")
+	fs.Var((*stringsFlag)(&qp.Tags), "tag", "tag (repeatable, all must match)")
+	fs.Var((*stringsFlag)(&qp.Types), "type", "document type (repeatable, all must match)")
+	fs.Var((*stringsFlag)(&qp.Cids), "cid", "cid (repeatable)")
+	fs.StringVar(&qp.Author, "author", "", "username, pid or pid/username")
+	fs.StringVar(&qp.Title, "title", "", "title")
+	fs.StringVar(&qp.After, "after", "", "date (2006-01-02)")
+	fs.StringVar(&qp.Before, "before", "", "date (2006-01-02)")
+	fs.StringVar(&qp.Text, "text", "", "full text search, ordered by relevance")
+	fs.StringVar(&qp.Order, "order", "newer", "newer or older")
+	fs.IntVar(&qp.Offset, "offset", 0, "number of documents to skip")
+	fs.IntVar(&qp.Limit, "limit", 0, "maximum number of documents (0: no limit)")
+	fs.BoolVar(&qp.Retracted, "retracted", false, "include retracted documents")
+	fs.Parse(args)
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	s.wait()
+
+	docs, err := qp.Documents(s.st)
+	if err != nil {
+		return err
+	}
+	return printJSON(docs)
+}
+
+func runCat(args []string) error {
+	fs := flag.NewFlagSet("cat", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	out := fs.String("o", "", "output file (default: stdout)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("cat takes one cid")
+	}
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	r, err := s.st.Ipfs().GetReader(fs.Arg(0), *cf.timeout)
+	if err != nil {
+		return err
+	}
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	out := fs.String("o", "", "output archive")
+	manifest := fs.Bool("manifest", false, "export only the manifest")
+	delta := fs.String("delta", "", "export the delta against this archive or manifest of the other side")
+	fs.Parse(args)
+	if *out == "" {
+		return errors.New("-o is required")
+	}
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	s.wait()
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch {
+	case *manifest:
+		if err := s.st.ExportManifest(f); err != nil {
+			return err
+		}
+		return printJSON(map[string]string{"manifest": *out})
+	case *delta != "":
+		known, err := os.Open(*delta)
+		if err != nil {
+			return err
+		}
+		defer known.Close()
+		fi, err := known.Stat()
+		if err != nil {
+			return err
+		}
+		report, err := s.st.ExportDelta(f, known, fi.Size())
+		if err != nil {
+			return err
+		}
+		return printJSON(api.NewReport(report))
+	default:
+		report, err := s.st.ExportArchive(f)
+		if err != nil {
+			return err
+		}
+		return printJSON(api.NewReport(report))
+	}
+}
+
+func runFeed(args []string) error {
+	fs := flag.NewFlagSet("feed", flag.ExitOnError)
+	cf := newCommonFlags(fs)
+	qp := &api.QueryParams{}
+	fs.StringVar(&qp.Expr, "q", "", "query expression, e.g. 'tag:defense'")
+	fs.StringVar(&qp.Text, "text", "", "full text search, ordered by relevance")
+	fs.StringVar(&qp.Order, "order", "newer", "newer or older")
+	fs.IntVar(&qp.Limit, "limit", 0, "maximum number of entries (default 50)")
+	fs.BoolVar(&qp.Retracted, "retracted", false, "include retracted documents")
+	title := fs.String("title", "", "feed title (default: the store address)")
+	link := fs.String("link", "", "base url of the document pages, e.g. a gateway")
+	out := fs.String("o", "", "output file (default: stdout)")
+	fs.Parse(args)
+
+	s, err := openSession(cf)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	s.wait()
+
+	if *title == "" {
+		*title = s.st.Address()
+	}
+	m, err := api.Feed(s.st, qp, *title, *link)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(m)
+		return err
+	}
+	return os.WriteFile(*out, m, 0644)
+}
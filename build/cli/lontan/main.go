@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	i2p "github.com/pilinsin/go-libp2p-i2p"
+	store "github.com/pilinsin/lontan/store"
+)
+
+const usage = `usage: lontan <command> -store <address> [flags] [args]
+
+commands:
+  put -name NAME -title TITLE -description DESC [-keystore FILE [-user NAME]] [-tag TAG]... [-text TEXT]... FILE...
+  get [-revision N] KEY
+  query [-q EXPR] [-tag TAG]... [-type TYPE]... [-author AUTHOR] [-title TITLE] [-cid CID]...
+        [-after DATE] [-before DATE] [-text TEXT] [-order newer|older] [-offset N] [-limit N] [-retracted]
+  cat [-o FILE] CID
+  export -o FILE [-manifest | -delta KNOWN]
+  feed [-q EXPR] [-text TEXT] [-order newer|older] [-limit N] [-title TITLE] [-link URL] [-o FILE]
+
+The output is JSON, except for cat and feed (Atom XML).
+put reads the passphrase of -keystore from LONTAN_PASSPHRASE.
+Run "lontan <command> -h" for the flags of a command.`
+
+type stringsFlag []string
+
+func (sf *stringsFlag) String() string { return strings.Join(*sf, ",") }
+func (sf *stringsFlag) Set(s string) error {
+	*sf = append(*sf, s)
+	return nil
+}
+
+// commonFlags are the flags of all commands.
+// The passphrase of the keystore is read from LONTAN_PASSPHRASE, so that it is not shown in the process list.
+type commonFlags struct {
+	addr     *string
+	dir      *string
+	keystore *string
+	user     *string
+	identity *string
+	sync     *time.Duration
+	timeout  *time.Duration
+}
+
+func newCommonFlags(fs *flag.FlagSet) *commonFlags {
+	return &commonFlags{
+		addr:     fs.String("store", os.Getenv("LONTAN_STORE"), "document store address (bootstrap list address/title/store address)"),
+		dir:      fs.String("dir", "", "local store directory (default: the one the GUI uses next to the binary)"),
+		keystore: fs.String("keystore", os.Getenv("LONTAN_KEYSTORE"), "keystore or exported identity file for put (passphrase in LONTAN_PASSPHRASE)"),
+		user:     fs.String("user", os.Getenv("LONTAN_USER"), "identity name in the keystore (default: the default identity of the store, or the only one)"),
+		identity: fs.String("identity", os.Getenv("LONTAN_IDENTITY"), "user identity string of older versions, instead of -keystore"),
+		sync:     fs.Duration("sync", 10*time.Second, "time to sync with peers before reading and after writing"),
+		timeout:  fs.Duration("timeout", time.Minute, "timeout to fetch data"),
+	}
+}
+
+// userIdentity returns the identity selected by the flags.
+// Without identity, the user is anonymous with a disposable key pair.
+func (cf *commonFlags) userIdentity() (*store.UserIdentity, error) {
+	if *cf.keystore != "" {
+		m, err := os.ReadFile(*cf.keystore)
+		if err != nil {
+			return nil, err
+		}
+		passphrase := os.Getenv("LONTAN_PASSPHRASE")
+		if passphrase == "" {
+			return nil, errors.New("LONTAN_PASSPHRASE is not set")
+		}
+		return store.KeystoreIdentity(m, passphrase, *cf.user, *cf.addr)
+	}
+	ui := store.AnonymousIdentity()
+	if *cf.identity != "" {
+		if err := ui.FromString(*cf.identity); err != nil {
+			return nil, errors.New("invalid user identity")
+		}
+	}
+	return ui, nil
+}
+
+// session is a loaded store with the i2p router it runs on.
+type session struct {
+	rt   *i2p.I2pRouter
+	st   store.IDocumentStore
+	ui   *store.UserIdentity
+	sync time.Duration
+}
+
+func openSession(cf *commonFlags) (*session, error) {
+	if *cf.addr == "" {
+		return nil, errors.New("-store is not set")
+	}
+	dir := *cf.dir
+	if dir == "" {
+		var err error
+		dir, err = store.StoreDir(*cf.addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ui, err := cf.userIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	rt := i2p.NewI2pRouter()
+	if err := rt.Start(); err != nil {
+		return nil, err
+	}
+	st, err := store.LoadDocumentStore(*cf.addr, dir)
+	if err != nil {
+		rt.Stop()
+		return nil, err
+	}
+	st.SetUserIdentity(ui)
+	return &session{rt, st, ui, *cf.sync}, nil
+}
+
+func (s *session) wait() {
+	time.Sleep(s.sync)
+}
+
+func (s *session) Close() {
+	s.st.Close()
+	s.rt.Stop()
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmds := map[string]func([]string) error{
+		"put":    runPut,
+		"get":    runGet,
+		"query":  runQuery,
+		"cat":    runCat,
+		"export": runExport,
+		"feed":   runFeed,
+	}
+	cmd, ok := cmds[os.Args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type config struct {
+	// local data (stores, bootstrap key and address) is kept under DataDir
+	DataDir string `json:"dataDir"`
+	// run a bootstrap node
+	Bootstrap bool `json:"bootstrap"`
+	// bootstrap list addresses which the bootstrap node connects to
+	Bootstraps []string `json:"bootstraps"`
+	// document store addresses (bootstrap list address/title/store address) to mirror
+	Mirrors []string `json:"mirrors"`
+	// read-only web gateways on localhost: store address -> port
+	Gateways map[string]int `json:"gateways"`
+	// seconds between progress logs
+	ProgressInterval int `json:"progressInterval"`
+}
+
+func loadConfig(path string) (*config, error) {
+	m, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(m, cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.DataDir == "" {
+		return nil, errors.New("dataDir is not set")
+	}
+	if !filepath.IsAbs(cfg.DataDir) {
+		cfg.DataDir = filepath.Join(filepath.Dir(path), cfg.DataDir)
+	}
+	if !cfg.Bootstrap && len(cfg.Mirrors) == 0 && len(cfg.Gateways) == 0 {
+		return nil, errors.New("nothing to run: set bootstrap, mirrors or gateways")
+	}
+	if cfg.ProgressInterval <= 0 {
+		cfg.ProgressInterval = 60
+	}
+	return cfg, nil
+}
+
+func (cfg *config) progressInterval() time.Duration {
+	return time.Duration(cfg.ProgressInterval) * time.Second
+}
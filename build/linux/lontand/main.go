@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	host "github.com/libp2p/go-libp2p-core/host"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+
+	i2p "github.com/pilinsin/go-libp2p-i2p"
+	gateway "github.com/pilinsin/lontan/gateway"
+	store "github.com/pilinsin/lontan/store"
+	pv "github.com/pilinsin/p2p-verse"
+)
+
+// bootstrapSeed returns the seed of the bootstrap node key, made at the first run.
+// The peer id is kept over restarts, but the i2p address is not,
+// so the current bootstrap list address is written to <dataDir>/bootstrap.
+func bootstrapSeed(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, "bootstrap.key")
+	seed, err := os.ReadFile(path)
+	if err == nil && len(seed) == 32 {
+		return seed, nil
+	}
+	seed = make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, seed, 0600); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+func startBootstrap(cfg *config) (pv.IBootstrap, error) {
+	seed, err := bootstrapSeed(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	hGen := func(seeds ...io.Reader) (host.Host, error) {
+		return i2p.NewI2pHost(bytes.NewReader(seed))
+	}
+
+	others := make([]peer.AddrInfo, 0)
+	for _, bAddr := range cfg.Bootstraps {
+		others = append(others, pv.AddrInfosFromString(bAddr)...)
+	}
+	b, err := pv.NewBootstrap(hGen, others...)
+	if err != nil {
+		return nil, err
+	}
+
+	bAddr := pv.AddrInfosToString(append(b.ConnectedPeers(), b.AddrInfo())...)
+	if err := os.WriteFile(filepath.Join(cfg.DataDir, "bootstrap"), []byte(bAddr), 0644); err != nil {
+		b.Close()
+		return nil, err
+	}
+	log.Println("bootstrap list address:", bAddr)
+	return b, nil
+}
+
+func storeDir(dataDir, addr string) string {
+	sum := sha256.Sum256([]byte(addr))
+	return filepath.Join(dataDir, "stores", hex.EncodeToString(sum[:]))
+}
+
+func startMirrors(cfg *config) map[string]store.IDocumentStore {
+	stores := make(map[string]store.IDocumentStore)
+	for _, addr := range cfg.Mirrors {
+		st, err := store.LoadDocumentStore(addr, storeDir(cfg.DataDir, addr))
+		if err != nil {
+			log.Println("load error", addr, err)
+			continue
+		}
+		if err := st.StartMirror(); err != nil {
+			log.Println("mirror error", addr, err)
+			st.Close()
+			continue
+		}
+		stores[addr] = st
+		log.Println("mirroring", addr)
+	}
+	return stores
+}
+
+// startGateways serves the read-only web gateways, loading the stores which are not mirrored.
+func startGateways(cfg *config, stores map[string]store.IDocumentStore) []*http.Server {
+	srvs := make([]*http.Server, 0, len(cfg.Gateways))
+	for addr, port := range cfg.Gateways {
+		st, ok := stores[addr]
+		if !ok {
+			var err error
+			st, err = store.LoadDocumentStore(addr, storeDir(cfg.DataDir, addr))
+			if err != nil {
+				log.Println("load error", addr, err)
+				continue
+			}
+			stores[addr] = st
+		}
+		srv := gateway.NewServer(st, port)
+		ln, err := net.Listen("tcp", srv.Addr)
+		if err != nil {
+			log.Println("gateway error", addr, err)
+			continue
+		}
+		go srv.Serve(ln)
+		srvs = append(srvs, srv)
+		log.Println("gateway of", addr, "on", srv.Addr)
+	}
+	return srvs
+}
+
+func logProgress(stores map[string]store.IDocumentStore) {
+	for addr, st := range stores {
+		mp := st.MirrorProgress()
+		if !mp.Enabled {
+			continue
+		}
+		log.Printf("%s: %d / %d data fetched, %d retrying\n", addr, mp.Fetched, mp.Cids, mp.Failing)
+	}
+}
+
+// run serves until ctx is done. The deferred cleanup runs before it returns an error,
+// so that main can exit with a failure status.
+func run(ctx context.Context, cfg *config) error {
+	rt := i2p.NewI2pRouter()
+	if err := rt.Start(); err != nil {
+		return errors.New("i2p router error: " + err.Error())
+	}
+	defer rt.Stop()
+	log.Println("i2p router on")
+
+	if cfg.Bootstrap {
+		b, err := startBootstrap(cfg)
+		if err != nil {
+			return errors.New("bootstrap error: " + err.Error())
+		}
+		defer b.Close()
+	}
+
+	stores := startMirrors(cfg)
+	defer func() {
+		for _, st := range stores {
+			st.Close()
+		}
+	}()
+	mirrors := len(stores)
+	srvs := startGateways(cfg, stores)
+	defer func() {
+		for _, srv := range srvs {
+			srv.Close()
+		}
+	}()
+	if !cfg.Bootstrap && mirrors == 0 && len(srvs) == 0 {
+		return errors.New("no bootstrap, mirror or gateway is running")
+	}
+
+	ticker := time.NewTicker(cfg.progressInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("shutting down")
+			return nil
+		case <-ticker.C:
+			logProgress(stores)
+		}
+	}
+}
+
+func main() {
+	cfgPath := flag.String("config", "lontand.json", "config file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		log.Fatalln("config error", err)
+	}
+	if err := os.MkdirAll(cfg.DataDir, 0700); err != nil {
+		log.Fatalln(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err = run(ctx, cfg)
+	stop()
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
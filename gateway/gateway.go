@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/pilinsin/lontan/api"
+	store "github.com/pilinsin/lontan/store"
+)
+
+const (
+	pageSize          = 20
+	textFetchTimeout  = time.Minute
+	storeTitleUnknown = "lontan"
+)
+
+// Gateway is a read-only web UI of a store, for readers without the app.
+// It takes the same search parameters as the local api (see api.QueryParamsFromValues).
+type Gateway struct {
+	st    store.IDocumentStore
+	title string
+}
+
+func New(st store.IDocumentStore) *Gateway {
+	title := storeTitleUnknown
+	if keys := strings.Split(st.Address(), "/"); len(keys) == 3 {
+		title = keys[1]
+	}
+	return &Gateway{st, title}
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "read-only", http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case r.URL.Path == "/":
+		g.search(w, r)
+	case r.URL.Path == "/feed":
+		api.ServeFeed(w, r, g.st, g.title, "http://"+r.Host)
+	case strings.HasPrefix(r.URL.Path, "/doc/"):
+		g.document(w, r, strings.TrimPrefix(r.URL.Path, "/doc/"))
+	case strings.HasPrefix(r.URL.Path, "/media/"):
+		g.media(w, r, strings.TrimPrefix(r.URL.Path, "/media/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type searchData struct {
+	Title      string
+	StoreTitle string
+	Params     *api.QueryParams
+	Docs       []*api.Document
+	Error      string
+	Prev, Next string
+	Feed       string
+}
+
+// pageURL returns the search url at offset, keeping the other parameters.
+func pageURL(v url.Values, offset int) string {
+	pv := url.Values{}
+	for key, vals := range v {
+		pv[key] = vals
+	}
+	pv.Set("offset", strconv.Itoa(offset))
+	return "/?" + pv.Encode()
+}
+
+// feedURL returns the feed url of the search, from the newest documents.
+func feedURL(v url.Values) string {
+	fv := url.Values{}
+	for key, vals := range v {
+		fv[key] = vals
+	}
+	fv.Del("offset")
+	return "/feed?" + fv.Encode()
+}
+
+func (g *Gateway) search(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	data := &searchData{Title: g.title, StoreTitle: g.title, Feed: feedURL(v)}
+	qp, err := api.QueryParamsFromValues(v)
+	if err != nil {
+		qp = &api.QueryParams{}
+		data.Error = err.Error()
+	}
+	data.Params = qp
+
+	if data.Error == "" {
+		if qp.Offset < 0 {
+			qp.Offset = 0
+		}
+		// one more document tells if there is a next page
+		qp.Limit = pageSize + 1
+		docs, err := qp.Documents(g.st)
+		if err != nil {
+			data.Error = err.Error()
+		} else {
+			if len(docs) > pageSize {
+				docs = docs[:pageSize]
+				data.Next = pageURL(v, qp.Offset+pageSize)
+			}
+			data.Docs = docs
+			if qp.Offset > 0 {
+				prev := qp.Offset - pageSize
+				if prev < 0 {
+					prev = 0
+				}
+				data.Prev = pageURL(v, prev)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	searchTemplate.ExecuteTemplate(w, "search", data)
+}
+
+type mediaData struct {
+	Type, Cid string
+	Text      string
+	Pages     []int
+	Error     string
+}
+
+type documentData struct {
+	Title      string
+	StoreTitle string
+	Params     *api.QueryParams
+	Doc        *api.Document
+	Media      []*mediaData
+}
+
+// loadMedia prepares what the page needs beforehand: the text, or the number of pdf pages.
+// Other media are loaded by the browser from /media.
+func (g *Gateway) loadMedia(tc api.TypedCid) *mediaData {
+	md := &mediaData{Type: tc.Type, Cid: tc.Cid}
+	if tc.Type != "text" && tc.Type != "pdf" {
+		return md
+	}
+	m, err := g.st.Ipfs().Get(tc.Cid, textFetchTimeout)
+	if err != nil {
+		md.Error = err.Error()
+		return md
+	}
+	if tc.Type == "text" {
+		md.Text = string(m)
+		return md
+	}
+	n, err := api.PdfPages(m)
+	if err != nil {
+		md.Error = err.Error()
+		return md
+	}
+	md.Pages = make([]int, n)
+	for idx := range md.Pages {
+		md.Pages[idx] = idx
+	}
+	return md
+}
+
+func (g *Gateway) document(w http.ResponseWriter, r *http.Request, key string) {
+	var nd *store.NamedDocument
+	var err error
+	if rev := r.URL.Query().Get("revision"); rev != "" {
+		n, perr := strconv.ParseInt(rev, 10, 64)
+		if perr != nil {
+			http.Error(w, "invalid revision", http.StatusBadRequest)
+			return
+		}
+		nd, err = g.st.GetRevision(key, n)
+	} else {
+		nd, err = g.st.Get(key)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	doc := api.NewDocument(nd)
+	data := &documentData{
+		Title:      doc.Title,
+		StoreTitle: g.title,
+		Params:     &api.QueryParams{},
+		Doc:        doc,
+		Media:      make([]*mediaData, 0),
+	}
+	// retracted documents show only the notice, like the app
+	if doc.Retraction == nil {
+		for _, tc := range doc.Cids {
+			data.Media = append(data.Media, g.loadMedia(tc))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	documentTemplate.ExecuteTemplate(w, "document", data)
+}
+
+func (g *Gateway) media(w http.ResponseWriter, r *http.Request, cid string) {
+	v := r.URL.Query()
+	page := 0
+	if p := v.Get("page"); p != "" {
+		var err error
+		if page, err = strconv.Atoi(p); err != nil {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	media, err := api.GetMedia(g.st, cid, page, v.Get("audio") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	api.ServeMedia(w, r, media)
+}
+
+// NewServer returns the server of the gateway on localhost.
+func NewServer(st store.IDocumentStore, port int) *http.Server {
+	return &http.Server{
+		Addr:    net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		Handler: New(st),
+	}
+}
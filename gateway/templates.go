@@ -0,0 +1,96 @@
+package gateway
+
+import "html/template"
+
+const layout = `{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="alternate" type="application/atom+xml" href="/feed">
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; padding: 1em; }
+.doc { border-top: 1px solid #ccc; padding: 0.5em 0; }
+.meta { color: #666; font-size: 0.9em; }
+.tag { margin-right: 0.5em; }
+.retracted { color: #a00; font-weight: bold; }
+img, video { max-width: 100%; }
+pre { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1><a href="/">{{.StoreTitle}}</a></h1>
+<form action="/" method="get">
+<input name="q" size="50" placeholder="tag:leak type:pdf after:2022-01-01" value="{{.Params.Expr}}">
+<input name="text" placeholder="full text" value="{{.Params.Text}}">
+<select name="order">
+<option value="newer"{{if ne .Params.Order "older"}} selected{{end}}>newer</option>
+<option value="older"{{if eq .Params.Order "older"}} selected{{end}}>older</option>
+</select>
+<label><input type="checkbox" name="retracted" value="true"{{if .Params.Retracted}} checked{{end}}>retracted</label>
+<input type="submit" value="search">
+</form>
+{{end}}
+{{define "footer"}}</body>
+</html>
+{{end}}
+{{define "tags"}}{{range .}}<a class="tag" href="/?tag={{.}}">{{.}}</a>{{end}}{{end}}
+{{define "signer"}}{{with .}}[{{.Fingerprint}}]{{if not .Valid}} <span class="retracted">invalid signature</span>{{end}}{{end}}{{end}}
+`
+
+const searchPage = `{{template "header" .}}
+{{if .Error}}<p class="retracted">{{.Error}}</p>{{end}}
+{{range .Docs}}
+<div class="doc">
+<a href="/doc/{{.Key}}"><b>{{.Title}}</b></a>
+<div class="meta">{{.Key}} {{template "signer" .Signer}} - {{.Time.Format "2006-01-02 15:04"}} - {{range .Types}}{{.}} {{end}}</div>
+{{if .Retraction}}<div class="retracted">retracted: {{.Retraction.Reason}}</div>{{end}}
+<div>{{template "tags" .Tags}}</div>
+<p>{{.Description}}</p>
+</div>
+{{else}}
+<p>no documents</p>
+{{end}}
+<p>
+{{if .Prev}}<a href="{{.Prev}}">prev</a>{{end}}
+{{if .Next}}<a href="{{.Next}}">next</a>{{end}}
+<a href="{{.Feed}}">feed of this search</a>
+</p>
+{{template "footer" .}}
+`
+
+const documentPage = `{{template "header" .}}
+{{with .Doc}}
+<h2>{{.Title}}</h2>
+<div class="meta">{{.Key}} {{template "signer" .Signer}} - {{.Time.Format "2006-01-02 15:04"}} - revision {{.Revision}}</div>
+<div>{{template "tags" .Tags}}</div>
+<p>{{.Description}}</p>
+{{if .Retraction}}<p class="retracted">retracted by the author ({{.Retraction.Time.Format "2006-01-02"}}): {{.Retraction.Reason}}</p>{{end}}
+{{end}}
+{{range .Media}}
+<div class="doc">
+{{if .Error}}<p class="retracted">{{.Type}} {{.Cid}}: {{.Error}}</p>
+{{else if eq .Type "text"}}<pre>{{.Text}}</pre>
+{{else if eq .Type "image"}}<img src="/media/{{.Cid}}">
+{{else if eq .Type "pdf"}}{{$cid := .Cid}}{{range .Pages}}<img src="/media/{{$cid}}?page={{.}}"><br>{{end}}
+{{else if eq .Type "video"}}<video id="v-{{.Cid}}" src="/media/{{.Cid}}" controls></video>
+<audio id="a-{{.Cid}}" src="/media/{{.Cid}}?audio=true"></audio>
+<script>
+(function() {
+	var v = document.getElementById("v-{{.Cid}}"), a = document.getElementById("a-{{.Cid}}");
+	v.onplay = function() { a.currentTime = v.currentTime; a.play(); };
+	v.onpause = function() { a.pause(); };
+	v.onseeked = function() { a.currentTime = v.currentTime; };
+})();
+</script>
+{{else if eq .Type "audio"}}<audio src="/media/{{.Cid}}" controls></audio>
+{{end}}
+</div>
+{{end}}
+{{template "footer" .}}
+`
+
+var (
+	searchTemplate   = template.Must(template.Must(template.New("search").Parse(layout)).Parse(searchPage))
+	documentTemplate = template.Must(template.Must(template.New("document").Parse(layout)).Parse(documentPage))
+)
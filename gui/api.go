@@ -0,0 +1,64 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	api "github.com/pilinsin/lontan/api"
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+const defaultApiPort = 8600
+
+// newApiForm starts and stops the local api, which serves the stores loaded in the GUI.
+func (gui *GUI) newApiForm() fyne.CanvasObject {
+	portEntry := widget.NewEntry()
+	portEntry.SetText(strconv.Itoa(defaultApiPort))
+	tokenLabel := gutil.NewCopyButton("api token")
+	noteLabel := widget.NewLabel("local api off")
+
+	apiCheck := widget.NewCheck("local api", nil)
+	apiCheck.OnChanged = func(checked bool) {
+		if !checked {
+			gui.stopApi()
+			noteLabel.SetText("local api off")
+			return
+		}
+		port, err := strconv.Atoi(portEntry.Text)
+		if err != nil {
+			noteLabel.SetText("invalid port")
+			apiCheck.SetChecked(false)
+			return
+		}
+		token, err := api.LoadToken(store.BaseDir("api_token"))
+		if err != nil {
+			noteLabel.SetText(fmt.Sprintln("api error", err))
+			apiCheck.SetChecked(false)
+			return
+		}
+		srv := api.NewServer(gui.stores, port, token)
+		if err := srv.Start(); err != nil {
+			noteLabel.SetText(fmt.Sprintln("api error", err))
+			apiCheck.SetChecked(false)
+			return
+		}
+		gui.api = srv
+		tokenLabel.SetText(token)
+		noteLabel.SetText(fmt.Sprintf("local api on 127.0.0.1:%d", port))
+	}
+
+	portObj := container.NewBorder(nil, nil, apiCheck, noteLabel, portEntry)
+	return container.NewVBox(portObj, tokenLabel.Render())
+}
+
+func (gui *GUI) stopApi() {
+	if gui.api != nil {
+		gui.api.Close()
+		gui.api = nil
+	}
+}
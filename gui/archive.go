@@ -0,0 +1,198 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+func archiveReportText(action string, report *store.ArchiveReport) string {
+	text := fmt.Sprintf("%s %d entries and %d data", action, report.Entries, report.Data)
+	if len(report.Failed) > 0 {
+		text += fmt.Sprintf("\n%d failed:\n%s", len(report.Failed), strings.Join(report.Failed, "\n"))
+	}
+	return text
+}
+
+func showArchiveReport(w fyne.Window, title, text string) {
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(label)
+	scroll.SetMinSize(fyne.NewSize(400, 200))
+	dialog.ShowCustom(title, "ok", scroll, w)
+}
+
+func exportArchiveDialog(w fyne.Window, st store.IDocumentStore, note *widget.Label) func() {
+	return func() {
+		onSelected := func(wc fyne.URIWriteCloser, err error) {
+			if wc == nil || err != nil {
+				return
+			}
+			go func() {
+				defer wc.Close()
+				note.SetText("exporting...")
+				report, err := st.ExportArchive(wc)
+				if err != nil {
+					note.SetText("export failed: " + err.Error())
+					return
+				}
+				note.SetText("")
+				showArchiveReport(w, "export", archiveReportText("exported", report))
+			}()
+		}
+		dialog.ShowFileSave(onSelected, w)
+	}
+}
+
+func openArchive(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, fi.Size(), nil
+}
+
+func importArchiveDialog(w fyne.Window, st store.IDocumentStore, note *widget.Label) func() {
+	return func() {
+		onSelected := func(rc fyne.URIReadCloser, err error) {
+			if rc == nil || err != nil {
+				return
+			}
+			path := rc.URI().Path()
+			rc.Close()
+			go func() {
+				note.SetText("importing...")
+				f, size, err := openArchive(path)
+				if err != nil {
+					note.SetText("import failed: " + err.Error())
+					return
+				}
+				defer f.Close()
+				report, err := st.ImportArchive(f, size)
+				if err != nil {
+					note.SetText("import failed: " + err.Error())
+					return
+				}
+				note.SetText("")
+				showArchiveReport(w, "import", archiveReportText("imported", report))
+			}()
+		}
+		dialog.ShowFileOpen(onSelected, w)
+	}
+}
+
+func exportManifestDialog(w fyne.Window, st store.IDocumentStore, note *widget.Label) func() {
+	return func() {
+		onSelected := func(wc fyne.URIWriteCloser, err error) {
+			if wc == nil || err != nil {
+				return
+			}
+			go func() {
+				defer wc.Close()
+				note.SetText("exporting manifest...")
+				if err := st.ExportManifest(wc); err != nil {
+					note.SetText("export failed: " + err.Error())
+					return
+				}
+				note.SetText("manifest exported")
+			}()
+		}
+		dialog.ShowFileSave(onSelected, w)
+	}
+}
+
+// the manifest of the other side is selected first, then the delta archive is saved.
+func exportDeltaDialog(w fyne.Window, st store.IDocumentStore, note *widget.Label) func() {
+	return func() {
+		onKnownSelected := func(rc fyne.URIReadCloser, err error) {
+			if rc == nil || err != nil {
+				return
+			}
+			knownPath := rc.URI().Path()
+			rc.Close()
+
+			onSelected := func(wc fyne.URIWriteCloser, err error) {
+				if wc == nil || err != nil {
+					return
+				}
+				go func() {
+					defer wc.Close()
+					note.SetText("exporting delta...")
+					f, size, err := openArchive(knownPath)
+					if err != nil {
+						note.SetText("export failed: " + err.Error())
+						return
+					}
+					defer f.Close()
+					report, err := st.ExportDelta(wc, f, size)
+					if err != nil {
+						note.SetText("export failed: " + err.Error())
+						return
+					}
+					note.SetText("")
+					showArchiveReport(w, "delta export", archiveReportText("exported", report))
+				}()
+			}
+			dialog.ShowFileSave(onSelected, w)
+		}
+		dialog.ShowFileOpen(onKnownSelected, w)
+	}
+}
+
+func verifyReportText(report *store.VerifyReport) string {
+	text := fmt.Sprintf("verified %d entries and %d data", report.Entries, report.Data)
+	if len(report.Issues) == 0 {
+		return text + "\nno issues"
+	}
+	issues := make([]string, len(report.Issues))
+	for idx, vi := range report.Issues {
+		issues[idx] = vi.String()
+	}
+	text += fmt.Sprintf("\n%d issues:\n%s", len(issues), strings.Join(issues, "\n"))
+	if missing := report.Missing(); len(missing) > 0 {
+		text += fmt.Sprintf("\n\nmissing data to re-seed:\n%s", strings.Join(missing, "\n"))
+	}
+	return text
+}
+
+func verifyStore(w fyne.Window, st store.IDocumentStore, note *widget.Label) func() {
+	return func() {
+		go func() {
+			note.SetText("verifying...")
+			report, err := st.Verify()
+			if err != nil {
+				note.SetText("verify failed: " + err.Error())
+				return
+			}
+			note.SetText("")
+			showArchiveReport(w, "verify", verifyReportText(report))
+		}()
+	}
+}
+
+// NewArchiveBar has the buttons to export the store to an archive and to import one.
+// For offline sync, the other side exports its manifest,
+// this side exports the delta against it and the other side imports the delta.
+// verify reports broken or missing items of the store.
+func NewArchiveBar(w fyne.Window, st store.IDocumentStore) fyne.CanvasObject {
+	note := widget.NewLabel("")
+	exportBtn := widget.NewButtonWithIcon("export", theme.DocumentSaveIcon(), exportArchiveDialog(w, st, note))
+	importBtn := widget.NewButtonWithIcon("import", theme.FolderOpenIcon(), importArchiveDialog(w, st, note))
+	manifestBtn := widget.NewButtonWithIcon("manifest", theme.ListIcon(), exportManifestDialog(w, st, note))
+	deltaBtn := widget.NewButtonWithIcon("delta", theme.DocumentSaveIcon(), exportDeltaDialog(w, st, note))
+	verifyBtn := widget.NewButtonWithIcon("verify", theme.ConfirmIcon(), verifyStore(w, st, note))
+	return container.NewHBox(exportBtn, importBtn, manifestBtn, deltaBtn, verifyBtn, note)
+}
@@ -0,0 +1,101 @@
+package gui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+func loadCommentThreads(st store.IDocumentStore, docKey string) ([]*store.CommentThread, error) {
+	ch, err := st.QueryComments(docKey)
+	if err != nil {
+		return nil, err
+	}
+	ncs := make([]*store.NamedComment, 0)
+	for nc := range ch {
+		ncs = append(ncs, nc)
+	}
+	return store.NewCommentThreads(ncs), nil
+}
+
+func newCommentThreadObj(th *store.CommentThread, onReply func(*store.NamedComment)) fyne.CanvasObject {
+	hline := widget.NewRichTextFromMarkdown("-----")
+	vk, _ := store.VerifyKeyOf(th.Name)
+	header := withIdenticon(vk, descriptionLabel(th.Author+"  "+th.Time.String()))
+	text := descriptionLabel(th.Text)
+	replyBtn := widget.NewButtonWithIcon("", theme.MailReplyIcon(), func() {
+		onReply(th.NamedComment)
+	})
+	comment := container.NewBorder(nil, nil, nil, replyBtn, container.NewVBox(header, text))
+
+	replies := make([]fyne.CanvasObject, len(th.Replies))
+	for idx, reply := range th.Replies {
+		replies[idx] = newCommentThreadObj(reply, onReply)
+	}
+	indent := widget.NewLabel("  ")
+	repliesObj := container.NewBorder(nil, nil, indent, nil, container.NewVBox(replies...))
+	return container.NewVBox(hline, comment, repliesObj)
+}
+
+func NewCommentPane(st store.IDocumentStore, docKey string, kr *keyring) (fyne.CanvasObject, gutil.Closer) {
+	noteLabel := widget.NewLabel("comments")
+	threadsObj := container.NewVBox()
+
+	uiObj, selectedIdentity, closer := newIdentitySelector(kr, st, noteLabel)
+	text := widget.NewMultiLineEntry()
+	text.SetPlaceHolder("comment")
+
+	parent := ""
+	replyLabel := widget.NewLabel("")
+	cancelReplyBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), nil)
+	replyObj := container.NewBorder(nil, nil, nil, cancelReplyBtn, replyLabel)
+	replyObj.Hide()
+	cancelReplyBtn.OnTapped = func() {
+		parent = ""
+		replyObj.Hide()
+	}
+	onReply := func(nc *store.NamedComment) {
+		parent = nc.Name
+		replyLabel.SetText("reply to " + nc.Author + ": " + extractDescription(nc.Text, 50))
+		replyObj.Show()
+	}
+
+	loadThreads := func() {
+		for _, obj := range threadsObj.Objects {
+			threadsObj.Remove(obj)
+		}
+		ths, err := loadCommentThreads(st, docKey)
+		if err != nil {
+			noteLabel.SetText("load comments error")
+			return
+		}
+		for _, th := range ths {
+			threadsObj.Add(newCommentThreadObj(th, onReply))
+		}
+		noteLabel.SetText(fmt.Sprintf("comments (%d)", len(ths)))
+	}
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), loadThreads)
+
+	sendBtn := widget.NewButtonWithIcon("", theme.MailSendIcon(), func() {
+		c := store.NewComment(docKey, parent, text.Text, time.Now().UTC())
+		if err := st.PutCommentAs(selectedIdentity(), c); err != nil {
+			noteLabel.SetText(fmt.Sprintln("comment error", err))
+			return
+		}
+		text.SetText("")
+		cancelReplyBtn.OnTapped()
+		loadThreads()
+	})
+
+	loadThreads()
+	header := container.NewBorder(nil, nil, refreshBtn, nil, noteLabel)
+	form := container.NewVBox(uiObj, replyObj, container.NewBorder(nil, nil, nil, sendBtn, text))
+	return container.NewVBox(header, threadsObj, form), closer
+}
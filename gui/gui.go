@@ -1,12 +1,8 @@
 package gui
 
 import (
-	"encoding/base64"
-	"path/filepath"
 	"strings"
 
-	"golang.org/x/crypto/argon2"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
@@ -14,6 +10,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	i2p "github.com/pilinsin/go-libp2p-i2p"
+	api "github.com/pilinsin/lontan/api"
 	gutil "github.com/pilinsin/lontan/gui/util"
 	store "github.com/pilinsin/lontan/store"
 	pv "github.com/pilinsin/p2p-verse"
@@ -23,24 +20,21 @@ func pageToTabItem(title string, page fyne.CanvasObject) *container.TabItem {
 	return container.NewTabItem(title, page)
 }
 
-func storeHash(title, stAddr string) string {
-	b := argon2.IDKey([]byte(stAddr), []byte(title), 1, 64*1024, 4, 64)
-	return base64.URLEncoding.EncodeToString(b)
-}
-
 type GUI struct {
 	rt     *i2p.I2pRouter
-	stores map[string]store.IDocumentStore
+	stores *store.StoreManager
 	bs     map[string]pv.IBootstrap
 	w      fyne.Window
 	size   fyne.Size
 	tabs   *container.AppTabs
 	page   *fyne.Container
+	api    *api.Server
+	keys   *keyring
 }
 
 func New(title string, width, height float32) *GUI {
 	rt := i2p.NewI2pRouter()
-	stores := make(map[string]store.IDocumentStore)
+	stores := store.NewStoreManager()
 	bs := make(map[string]pv.IBootstrap)
 	size := fyne.NewSize(width, height)
 	a := app.New()
@@ -49,7 +43,7 @@ func New(title string, width, height float32) *GUI {
 	win.Resize(size)
 	tabs := container.NewAppTabs()
 	page := container.NewMax()
-	return &GUI{rt, stores, bs, win, size, tabs, page}
+	return &GUI{rt, stores, bs, win, size, tabs, page, nil, newKeyring()}
 }
 
 func (gui *GUI) withRemove(page fyne.CanvasObject, closers ...gutil.Closer) fyne.CanvasObject {
@@ -76,16 +70,9 @@ func (gui *GUI) loadSearchPage(bAddr, stAddr string) (string, fyne.CanvasObject)
 	}
 	title, rawStAddr := addrs[0], addrs[1]
 
-	storesKey := storeHash(title, rawStAddr)
-	st, ok := gui.stores[storesKey]
-	if !ok {
-		baseDir := store.BaseDir(filepath.Join("stores", storesKey))
-		var err error
-		st, err = store.LoadDocumentStore(bAddr+"/"+stAddr, baseDir)
-		if err != nil {
-			return "", nil
-		}
-		gui.stores[storesKey] = st
+	_, st, err := gui.stores.Load(bAddr + "/" + title + "/" + rawStAddr)
+	if err != nil {
+		return "", nil
 	}
 
 	return title, gui.NewSearchPage(gui.w, title, st)
@@ -114,7 +101,8 @@ func (gui *GUI) loadPageForm() fyne.CanvasObject {
 func (gui *GUI) defaultPage(note *widget.Label) *container.TabItem {
 	newForm := gui.loadPageForm()
 	setup := gui.NewSetupPage()
-	return pageToTabItem("top page", container.NewBorder(newForm, note, nil, nil, setup))
+	bottom := container.NewVBox(gui.newApiForm(), note)
+	return pageToTabItem("top page", container.NewBorder(newForm, bottom, nil, nil, setup))
 }
 
 func (gui *GUI) initErrorPage() {
@@ -136,10 +124,8 @@ func (gui *GUI) i2pStart(i2pNote *widget.Label) {
 }
 
 func (gui *GUI) Close() {
-	for _, st := range gui.stores {
-		st.Close()
-		st = nil
-	}
+	gui.stopApi()
+	gui.stores.Close()
 	for _, b := range gui.bs {
 		b.Close()
 		b = nil
@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+const (
+	identiconCells    = 5
+	identiconCellSize = 8
+	identiconSize     = 24
+)
+
+// identiconImage draws a 5x5 pattern mirrored left to right, like the github ones.
+// The sha256 of the raw verify key gives the color and which cells are filled.
+func identiconImage(raw []byte) image.Image {
+	sum := sha256.Sum256(raw)
+	// darker colors stay visible on the light theme
+	fg := color.NRGBA{sum[0]/2 + 32, sum[1]/2 + 32, sum[2]/2 + 32, 0xff}
+	bg := color.NRGBA{0xf0, 0xf0, 0xf0, 0xff}
+
+	side := (identiconCells + 1) * identiconCellSize
+	img := image.NewNRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			img.SetNRGBA(x, y, bg)
+		}
+	}
+
+	margin := identiconCellSize / 2
+	half := (identiconCells + 1) / 2
+	for row := 0; row < identiconCells; row++ {
+		for col := 0; col < half; col++ {
+			bit := row*half + col
+			if sum[3+bit/8]>>(bit%8)&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconCells - 1 - col} {
+				x0, y0 := margin+c*identiconCellSize, margin+row*identiconCellSize
+				for y := y0; y < y0+identiconCellSize; y++ {
+					for x := x0; x < x0+identiconCellSize; x++ {
+						img.SetNRGBA(x, y, fg)
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// newIdenticon shows the identicon of a raw verify key, or a blank space without a key.
+func newIdenticon(raw []byte) fyne.CanvasObject {
+	if len(raw) == 0 {
+		return widget.NewLabel("")
+	}
+	icon := canvas.NewImageFromImage(identiconImage(raw))
+	icon.FillMode = canvas.ImageFillContain
+	icon.ScaleMode = canvas.ImageScalePixels
+	icon.SetMinSize(fyne.NewSize(identiconSize, identiconSize))
+	return icon
+}
+
+// withIdenticon puts the identicon of the author on the left of obj.
+func withIdenticon(raw []byte, obj fyne.CanvasObject) fyne.CanvasObject {
+	return container.NewBorder(nil, nil, newIdenticon(raw), nil, obj)
+}
+
+func signerKey(ndoc *store.NamedDocument) []byte {
+	if ndoc.Signer == nil {
+		return nil
+	}
+	return ndoc.Signer.Verify
+}
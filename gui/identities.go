@@ -0,0 +1,233 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+const noStoreName = "no store"
+
+// storeOptions returns the titles of the loaded stores and their StoreKeys.
+// Titles are suffixed when several stores have the same one.
+func storeOptions(sm *store.StoreManager) ([]string, map[string]string) {
+	titles := []string{noStoreName}
+	keys := make(map[string]string)
+	for _, key := range sm.Keys() {
+		st, ok := sm.Get(key)
+		if !ok {
+			continue
+		}
+		stKey, err := store.StoreKey(st.Address())
+		if err != nil {
+			continue
+		}
+		title := stKey
+		if addrs := strings.Split(st.Address(), "/"); len(addrs) == 3 {
+			title = addrs[1]
+		}
+		if _, exist := keys[title]; exist {
+			title += " (" + stKey[:8] + ")"
+		}
+		titles = append(titles, title)
+		keys[title] = stKey
+	}
+	return titles, keys
+}
+
+func exportIdentityDialog(w fyne.Window, ke *store.KeystoreEntry, passEntry *widget.Entry, note *widget.Label) func() {
+	return func() {
+		if passEntry.Text == "" {
+			note.SetText("file passphrase is empty")
+			return
+		}
+		onSelected := func(wc fyne.URIWriteCloser, err error) {
+			if wc == nil || err != nil {
+				return
+			}
+			defer wc.Close()
+			if err := store.ExportIdentity(wc, passEntry.Text, ke.Name, ke.Identity); err != nil {
+				note.SetText("export failed: " + err.Error())
+				return
+			}
+			note.SetText("exported " + ke.Name)
+		}
+		dialog.ShowFileSave(onSelected, w)
+	}
+}
+
+func importIdentitiesDialog(w fyne.Window, kr *keyring, passEntry *widget.Entry, note *widget.Label) func() {
+	return func() {
+		ks := kr.keystore()
+		if ks == nil {
+			note.SetText("unlock the keystore first")
+			return
+		}
+		onSelected := func(rc fyne.URIReadCloser, err error) {
+			if rc == nil || err != nil {
+				return
+			}
+			defer rc.Close()
+			kes, err := store.ImportIdentities(rc, passEntry.Text)
+			if err != nil {
+				note.SetText("import failed: " + err.Error())
+				return
+			}
+			failed := make([]string, 0)
+			for _, ke := range kes {
+				if err := ks.Add(ke.Name, ke.Identity); err != nil {
+					failed = append(failed, ke.Name+": "+err.Error())
+				}
+			}
+			kr.changed()
+			text := fmt.Sprintf("imported %d identities", len(kes)-len(failed))
+			if len(failed) > 0 {
+				text += fmt.Sprintf(", %d failed: %s", len(failed), strings.Join(failed, ", "))
+			}
+			note.SetText(text)
+		}
+		dialog.ShowFileOpen(onSelected, w)
+	}
+}
+
+// NewIdentitiesPage manages the identities of the keystore.
+// The default identity of a store is preselected wherever the store asks for an identity.
+func (gui *GUI) NewIdentitiesPage() (fyne.CanvasObject, gutil.Closer) {
+	kr := gui.keys
+	noteLabel := widget.NewLabel("identities")
+	unlockForm, closeForm := newUnlockForm(kr, noteLabel)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("user name")
+	createBtn := widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		ks := kr.keystore()
+		if ks == nil {
+			noteLabel.SetText("unlock the keystore first")
+			return
+		}
+		kp := store.NewKeyPair()
+		ui := store.NewUserIdentity(nameEntry.Text, kp.Verify(), kp.Sign())
+		if err := ks.Add(nameEntry.Text, ui); err != nil {
+			noteLabel.SetText(fmt.Sprintln("create error", err))
+			return
+		}
+		noteLabel.SetText("created " + nameEntry.Text)
+		nameEntry.SetText("")
+		kr.changed()
+	})
+	createForm := container.NewBorder(nil, nil, nil, createBtn, nameEntry)
+
+	filePassEntry := widget.NewPasswordEntry()
+	filePassEntry.SetPlaceHolder("file passphrase for import and export")
+	importBtn := widget.NewButtonWithIcon("import", theme.FolderOpenIcon(), importIdentitiesDialog(gui.w, kr, filePassEntry, noteLabel))
+	fileForm := container.NewBorder(nil, nil, nil, importBtn, filePassEntry)
+
+	// identity strings were the only form of identities before the keystore
+	legacyEntry := widget.NewPasswordEntry()
+	legacyEntry.SetPlaceHolder("legacy user identity string")
+	legacyBtn := widget.NewButtonWithIcon("import", theme.ContentPasteIcon(), func() {
+		ks := kr.keystore()
+		if ks == nil {
+			noteLabel.SetText("unlock the keystore first")
+			return
+		}
+		ui := &store.UserIdentity{}
+		if err := ui.FromString(legacyEntry.Text); err != nil {
+			noteLabel.SetText(fmt.Sprintln("invalid identity string", err))
+			return
+		}
+		if err := ks.Add(ui.UserName(), ui); err != nil {
+			noteLabel.SetText(fmt.Sprintln("import error", err))
+			return
+		}
+		noteLabel.SetText("imported " + ui.UserName())
+		legacyEntry.SetText("")
+		kr.changed()
+	})
+	legacyForm := container.NewBorder(nil, nil, nil, legacyBtn, legacyEntry)
+
+	titles, stKeys := storeOptions(gui.stores)
+	storeSelector := widget.NewSelect(titles, nil)
+	storeSelector.SetSelected(noStoreName)
+
+	list := container.NewVBox()
+	newRow := func(ks *store.Keystore, ke *store.KeystoreEntry) fyne.CanvasObject {
+		stKey, hasStore := stKeys[storeSelector.Selected]
+		defaultCheck := widget.NewCheck("default", nil)
+		if hasStore {
+			defaultCheck.SetChecked(ks.Default(stKey) == ke.Name)
+			defaultCheck.OnChanged = func(b bool) {
+				name := ""
+				if b {
+					name = ke.Name
+				}
+				if err := ks.SetDefault(stKey, name); err != nil {
+					noteLabel.SetText(fmt.Sprintln("default error", err))
+				}
+				kr.changed()
+			}
+		} else {
+			defaultCheck.Disable()
+		}
+
+		exportBtn := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), exportIdentityDialog(gui.w, ke, filePassEntry, noteLabel))
+		deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			msg := "delete " + ke.Name + "? documents signed by it can no longer be edited or retracted."
+			dialog.ShowConfirm("delete identity", msg, func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := ks.Remove(ke.Name); err != nil {
+					noteLabel.SetText(fmt.Sprintln("delete error", err))
+					return
+				}
+				kr.changed()
+			}, gui.w)
+		})
+
+		vk, _ := ke.Identity.Verify().Raw()
+		info := withIdenticon(vk, widget.NewLabel(ke.Name+"  "+ke.Identity.Fingerprint()))
+		return container.NewBorder(nil, nil, defaultCheck, container.NewHBox(exportBtn, deleteBtn), info)
+	}
+	reload := func() {
+		for _, obj := range list.Objects {
+			list.Remove(obj)
+		}
+		ks := kr.keystore()
+		if ks == nil {
+			return
+		}
+		for _, ke := range ks.Entries() {
+			list.Add(newRow(ks, ke))
+		}
+	}
+	storeSelector.OnChanged = func(string) { reload() }
+	refreshStoresBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		titles, stKeys = storeOptions(gui.stores)
+		storeSelector.Options = titles
+		if _, ok := stKeys[storeSelector.Selected]; !ok {
+			storeSelector.SetSelected(noStoreName)
+		}
+		storeSelector.Refresh()
+		reload()
+	})
+	reload()
+	unsubscribe := kr.subscribe(reload)
+	closer := func() error {
+		closeForm()
+		return unsubscribe()
+	}
+
+	stores := container.NewBorder(nil, nil, widget.NewLabel("defaults of"), refreshStoresBtn, storeSelector)
+	hline := widget.NewRichTextFromMarkdown("-----")
+	top := container.NewVBox(unlockForm, createForm, fileForm, legacyForm, stores, hline)
+	return container.NewBorder(top, noteLabel, nil, nil, container.NewVScroll(list)), closer
+}
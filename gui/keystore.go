@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"fmt"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+const anonymousName = "Anonymous"
+
+// keyring holds the keystore once it is unlocked, for the rest of the session.
+type keyring struct {
+	mutex     sync.Mutex
+	ks        *store.Keystore
+	listeners map[int]func()
+	nextID    int
+}
+
+func newKeyring() *keyring {
+	return &keyring{listeners: make(map[int]func())}
+}
+
+func (kr *keyring) keystore() *store.Keystore {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+	return kr.ks
+}
+
+func (kr *keyring) unlock(passphrase string) (*store.Keystore, error) {
+	kr.mutex.Lock()
+	if kr.ks != nil {
+		defer kr.mutex.Unlock()
+		return kr.ks, nil
+	}
+	ks, err := store.OpenKeystore(store.KeystorePath(), passphrase)
+	if err != nil {
+		kr.mutex.Unlock()
+		return nil, err
+	}
+	kr.ks = ks
+	kr.mutex.Unlock()
+
+	kr.changed()
+	return ks, nil
+}
+
+// subscribe calls f when the keystore is unlocked or its identities change.
+// The returned closer unsubscribes f, and is called when the tab of f is closed.
+func (kr *keyring) subscribe(f func()) gutil.Closer {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+	id := kr.nextID
+	kr.nextID++
+	kr.listeners[id] = f
+	return func() error {
+		kr.mutex.Lock()
+		defer kr.mutex.Unlock()
+		delete(kr.listeners, id)
+		return nil
+	}
+}
+func (kr *keyring) changed() {
+	kr.mutex.Lock()
+	fs := make([]func(), 0, len(kr.listeners))
+	for _, f := range kr.listeners {
+		fs = append(fs, f)
+	}
+	kr.mutex.Unlock()
+	for _, f := range fs {
+		f()
+	}
+}
+
+func newUnlockForm(kr *keyring, noteLabel *widget.Label) (fyne.CanvasObject, gutil.Closer) {
+	passEntry := widget.NewPasswordEntry()
+	passEntry.SetPlaceHolder("keystore passphrase")
+	unlockBtn := widget.NewButtonWithIcon("", theme.LoginIcon(), func() {
+		if _, err := kr.unlock(passEntry.Text); err != nil {
+			noteLabel.SetText(fmt.Sprintln("unlock error", err))
+			return
+		}
+		passEntry.SetText("")
+	})
+	form := container.NewBorder(nil, nil, nil, unlockBtn, passEntry)
+	hideUnlocked := func() {
+		if kr.keystore() != nil {
+			form.Hide()
+		}
+	}
+	hideUnlocked()
+	return form, kr.subscribe(hideUnlocked)
+}
+
+// newIdentitySelector unlocks the keystore and selects one of its identities,
+// starting from the default identity of the store.
+// The returned func gives the selected identity, nil for an anonymous one.
+func newIdentitySelector(kr *keyring, st store.IDocumentStore, noteLabel *widget.Label) (fyne.CanvasObject, func() *store.UserIdentity, gutil.Closer) {
+	stKey, _ := store.StoreKey(st.Address())
+	sel := widget.NewSelect([]string{anonymousName}, nil)
+	sel.SetSelected(anonymousName)
+	reload := func() {
+		ks := kr.keystore()
+		names := []string{anonymousName}
+		if ks != nil {
+			for _, ke := range ks.Entries() {
+				names = append(names, ke.Name)
+			}
+		}
+		sel.Options = names
+		sel.Refresh()
+
+		if ks == nil {
+			return
+		}
+		if _, err := ks.Get(sel.Selected); err != nil || sel.Selected == anonymousName {
+			if name := ks.Default(stKey); name != "" {
+				sel.SetSelected(name)
+			} else {
+				sel.SetSelected(anonymousName)
+			}
+		}
+	}
+	reload()
+	unsubscribe := kr.subscribe(reload)
+
+	selected := func() *store.UserIdentity {
+		ks := kr.keystore()
+		if ks == nil || sel.Selected == anonymousName {
+			return nil
+		}
+		ui, err := ks.Get(sel.Selected)
+		if err != nil {
+			return nil
+		}
+		return ui
+	}
+	unlockForm, closeForm := newUnlockForm(kr, noteLabel)
+	closer := func() error {
+		closeForm()
+		return unsubscribe()
+	}
+	obj := container.NewVBox(unlockForm, container.NewBorder(nil, nil, widget.NewLabel("identity"), nil, sel))
+	return obj, selected, closer
+}
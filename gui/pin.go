@@ -0,0 +1,183 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+const mib = 1024 * 1024
+
+func pinText(st store.IDocumentStore, key string) string {
+	if st.IsPinned(key) {
+		return "unpin"
+	}
+	return "pin"
+}
+
+func NewPinButton(st store.IDocumentStore, key string) fyne.CanvasObject {
+	noteLabel := widget.NewLabel("")
+	var pinBtn *widget.Button
+	pinBtn = widget.NewButtonWithIcon(pinText(st, key), theme.ContentAddIcon(), func() {
+		go func() {
+			var err error
+			if st.IsPinned(key) {
+				err = st.Unpin(key)
+			} else {
+				noteLabel.SetText("pinning...")
+				err = st.Pin(key)
+			}
+			if err != nil {
+				noteLabel.SetText(fmt.Sprintln("pin error", err))
+			} else {
+				noteLabel.SetText("")
+			}
+			pinBtn.SetText(pinText(st, key))
+		}()
+	})
+	return container.NewHBox(pinBtn, noteLabel)
+}
+
+func usageText(st store.IDocumentStore) string {
+	usage, err := st.DiskUsage()
+	if err != nil {
+		return fmt.Sprintln("disk usage error", err)
+	}
+	quota := st.Quota()
+	if quota == 0 {
+		return fmt.Sprintf("disk usage: %s (no quota)", formatSize(usage))
+	}
+	return fmt.Sprintf("disk usage: %s / %s", formatSize(usage), formatSize(quota))
+}
+
+func gcReportText(report *store.GCReport) string {
+	if len(report.Incomplete) > 0 {
+		return fmt.Sprintf("gc removed nothing: %d data are only partly local (pin or open them to fetch the rest)\n%s",
+			len(report.Incomplete), strings.Join(report.Incomplete, "\n"))
+	}
+	if report.DryRun {
+		return fmt.Sprintf("gc would free %s in %d unreferenced blocks", formatSize(report.Freed), report.Blocks)
+	}
+	return fmt.Sprintf("gc freed %s in %d unreferenced blocks", formatSize(report.Freed), report.Blocks)
+}
+
+func mirrorText(mp *store.MirrorProgress) string {
+	if !mp.Enabled {
+		return "mirror: off"
+	}
+	text := fmt.Sprintf("mirror: %d / %d data fetched", mp.Fetched, mp.Cids)
+	if mp.Failing > 0 {
+		text += fmt.Sprintf(", %d retrying", mp.Failing)
+	}
+	return text
+}
+
+// NewPinPage lists the pinned documents and sets the disk quota.
+// Unpinned data is evicted least recently used first when the quota is exceeded,
+// and blocks which no document references are removed by gc.
+// A mirror fetches and keeps the data of all documents.
+func NewPinPage(st store.IDocumentStore) fyne.CanvasObject {
+	usageLabel := widget.NewLabel("")
+	noteLabel := widget.NewLabel("")
+	pins := container.NewVBox()
+
+	mirrorLabel := widget.NewLabel("")
+	mirrorCheck := widget.NewCheck("mirror all documents", nil)
+	mirrorCheck.SetChecked(st.Mirroring())
+
+	var reload func()
+	reload = func() {
+		usageLabel.SetText(usageText(st))
+		mirrorLabel.SetText(mirrorText(st.MirrorProgress()))
+		pins.Objects = nil
+		pis, err := st.Pins()
+		if err != nil {
+			noteLabel.SetText(fmt.Sprintln("pins error", err))
+			pins.Refresh()
+			return
+		}
+		for _, pi := range pis {
+			key := pi.Key
+			unpinBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				if err := st.Unpin(key); err != nil {
+					noteLabel.SetText(fmt.Sprintln("unpin error", err))
+				}
+				reload()
+			})
+			text := fmt.Sprintf("%s (%s, pinned %s)", key, formatSize(pi.Size), pi.Time.Local().Format("2006-01-02"))
+			pins.Add(container.NewBorder(nil, nil, unpinBtn, nil, widget.NewLabel(text)))
+		}
+		pins.Refresh()
+	}
+
+	quotaEntry := widget.NewEntry()
+	quotaEntry.SetPlaceHolder("quota in MiB (0: no limit)")
+	if quota := st.Quota(); quota > 0 {
+		quotaEntry.SetText(strconv.FormatInt(quota/mib, 10))
+	}
+	quotaBtn := widget.NewButton("set quota", func() {
+		n, err := strconv.ParseInt(quotaEntry.Text, 10, 64)
+		if err != nil {
+			noteLabel.SetText("invalid quota")
+			return
+		}
+		if err := st.SetQuota(n * mib); err != nil {
+			noteLabel.SetText(fmt.Sprintln("quota error", err))
+			return
+		}
+		noteLabel.SetText("quota set")
+		reload()
+	})
+	evictBtn := widget.NewButton("evict now", func() {
+		go func() {
+			noteLabel.SetText("evicting...")
+			freed, err := st.EnforceQuota()
+			if err != nil {
+				noteLabel.SetText(fmt.Sprintln("evict error", err))
+				return
+			}
+			noteLabel.SetText("freed " + formatSize(freed))
+			reload()
+		}()
+	})
+	gcBtn := func(text string, dryRun bool) *widget.Button {
+		return widget.NewButton(text, func() {
+			go func() {
+				noteLabel.SetText("collecting garbage...")
+				report, err := st.GC(dryRun)
+				if err != nil {
+					noteLabel.SetText(fmt.Sprintln("gc error", err))
+					return
+				}
+				noteLabel.SetText(gcReportText(report))
+				reload()
+			}()
+		})
+	}
+	mirrorCheck.OnChanged = func(checked bool) {
+		var err error
+		if checked {
+			err = st.StartMirror()
+		} else {
+			err = st.StopMirror()
+		}
+		if err != nil {
+			noteLabel.SetText(fmt.Sprintln("mirror error", err))
+		}
+		reload()
+	}
+	reloadBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), reload)
+
+	quotaObj := container.NewBorder(nil, nil, nil, container.NewHBox(quotaBtn, evictBtn, gcBtn("gc dry run", true), gcBtn("gc", false)), quotaEntry)
+	mirrorObj := container.NewHBox(mirrorCheck, mirrorLabel)
+	top := container.NewVBox(container.NewBorder(nil, nil, nil, reloadBtn, usageLabel), quotaObj, mirrorObj, noteLabel)
+	reload()
+	return container.NewBorder(top, nil, nil, nil, container.NewVScroll(pins))
+}
@@ -0,0 +1,121 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+var confidence = []string{
+	"low",
+	"medium",
+	"high",
+}
+
+func verdictNames() []string {
+	names := make([]string, len(store.Verdicts))
+	for idx, v := range store.Verdicts {
+		names[idx] = v.String()
+	}
+	return names
+}
+func nameToVerdict(name string) (store.Verdict, bool) {
+	for _, v := range store.Verdicts {
+		if v.String() == name {
+			return v, true
+		}
+	}
+	return -1, false
+}
+func nameToConfidence(name string) (int, bool) {
+	for idx, c := range confidence {
+		if c == name {
+			return store.MinConfidence + idx, true
+		}
+	}
+	return -1, false
+}
+
+func ratingText(rs *store.RatingSummary) string {
+	if rs == nil || rs.Total() == 0 {
+		return "not rated"
+	}
+	score := strconv.FormatFloat(rs.Score, 'f', 2, 64)
+	return fmt.Sprintf("credibility %s (%d ratings)", score, rs.Total())
+}
+func ratingBreakdownText(rs *store.RatingSummary) string {
+	if rs == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %d, %s: %d, %s: %d",
+		store.Authentic, rs.Authentic,
+		store.Doubtful, rs.Doubtful,
+		store.Fabricated, rs.Fabricated,
+	)
+}
+
+func ratingBadge(rs *store.RatingSummary) fyne.CanvasObject {
+	badge := &widget.Label{
+		Text:      ratingText(rs),
+		Wrapping:  fyne.TextTruncate,
+		TextStyle: fyne.TextStyle{Italic: true},
+	}
+	badge.ExtendBaseWidget(badge)
+	return badge
+}
+
+func NewRatingPane(st store.IDocumentStore, docKey string, kr *keyring) (fyne.CanvasObject, gutil.Closer) {
+	noteLabel := widget.NewLabel("")
+	scoreLabel := widget.NewLabel("")
+	breakdownLabel := widget.NewLabel("")
+	loadSummary := func() {
+		rs, err := st.RatingSummary(docKey)
+		if err != nil {
+			noteLabel.SetText("load ratings error")
+			return
+		}
+		scoreLabel.SetText(ratingText(rs))
+		breakdownLabel.SetText(ratingBreakdownText(rs))
+	}
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), loadSummary)
+
+	uiObj, selectedIdentity, closer := newIdentitySelector(kr, st, noteLabel)
+	verdictSelector := widget.NewSelect(verdictNames(), nil)
+	verdictSelector.PlaceHolder = "verdict"
+	confSelector := widget.NewSelect(confidence, nil)
+	confSelector.PlaceHolder = "confidence"
+
+	rateBtn := widget.NewButtonWithIcon("", theme.ConfirmIcon(), func() {
+		verdict, ok := nameToVerdict(verdictSelector.Selected)
+		if !ok {
+			noteLabel.SetText("verdict is not selected")
+			return
+		}
+		conf, ok := nameToConfidence(confSelector.Selected)
+		if !ok {
+			noteLabel.SetText("confidence is not selected")
+			return
+		}
+		r := store.NewRating(docKey, verdict, conf, time.Now().UTC())
+		if err := st.PutRatingAs(selectedIdentity(), r); err != nil {
+			noteLabel.SetText(fmt.Sprintln("rating error", err))
+			return
+		}
+		noteLabel.SetText("rated")
+		loadSummary()
+	})
+
+	loadSummary()
+	summary := container.NewBorder(nil, nil, refreshBtn, nil, container.NewVBox(scoreLabel, breakdownLabel))
+	selectors := container.NewHBox(verdictSelector, confSelector, rateBtn)
+	form := container.NewVBox(uiObj, container.NewBorder(nil, nil, selectors, nil, noteLabel))
+	return container.NewVBox(summary, form), closer
+}
@@ -0,0 +1,49 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	gutil "github.com/pilinsin/lontan/gui/util"
+	store "github.com/pilinsin/lontan/store"
+)
+
+func retractionText(r *store.Retraction) string {
+	return fmt.Sprintf("retracted by the author (%s): %s", r.Time.String(), r.Reason)
+}
+
+func retractionNotice(r *store.Retraction) fyne.CanvasObject {
+	notice := &widget.Label{
+		Text:      retractionText(r),
+		Wrapping:  fyne.TextWrapWord,
+		TextStyle: fyne.TextStyle{Bold: true},
+	}
+	notice.ExtendBaseWidget(notice)
+	return container.NewBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), nil, notice)
+}
+
+func NewRetractForm(st store.IDocumentStore, nmDoc *store.NamedDocument, kr *keyring) (fyne.CanvasObject, gutil.Closer) {
+	if nmDoc.Retraction != nil {
+		return container.NewVBox(), nil
+	}
+
+	noteLabel := widget.NewLabel("only the author can retract")
+	uiObj, selectedIdentity, closer := newIdentitySelector(kr, st, noteLabel)
+	reason := widget.NewEntry()
+	reason.SetPlaceHolder("reason")
+
+	retractBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if err := st.RetractAs(selectedIdentity(), nmDoc.Name, reason.Text); err != nil {
+			noteLabel.SetText(fmt.Sprintln("retract error", err))
+			return
+		}
+		noteLabel.SetText("retracted")
+	})
+
+	form := container.NewVBox(uiObj, reason, container.NewBorder(nil, nil, retractBtn, nil, noteLabel))
+	return widget.NewAccordion(widget.NewAccordionItem("retract", form)), closer
+}
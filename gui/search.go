@@ -1,7 +1,9 @@
 package gui
 
 import (
+	"strconv"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -20,6 +22,8 @@ var mode = []string{
 	"cid",
 	"document type",
 	"tag",
+	"full text",
+	"query",
 }
 var order = []string{
 	"newer",
@@ -28,7 +32,11 @@ var order = []string{
 
 func (gui *GUI) NewSearchPage(w fyne.Window, title string, st store.IDocumentStore) fyne.CanvasObject {
 	uploadBtn := widget.NewButtonWithIcon("", theme.UploadIcon(), func() {
-		gui.addPageToTabs(title+"_upload", NewUploadPage(w, st))
+		upage, closer := NewUploadPage(w, st, gui.keys)
+		gui.addPageToTabs(title+"_upload", upage, closer)
+	})
+	pinsBtn := widget.NewButtonWithIcon("pins", theme.StorageIcon(), func() {
+		gui.addPageToTabs(title+"_pins", NewPinPage(st))
 	})
 
 	modeSelector := widget.NewSelect(mode, nil)
@@ -36,62 +44,165 @@ func (gui *GUI) NewSearchPage(w fyne.Window, title string, st store.IDocumentSto
 	searchEntry.SetPlaceHolder("search text")
 	orderBtn := widget.NewSelect(order, nil)
 	orderBtn.Selected = order[0]
+	retractedCheck := widget.NewCheck("retracted", nil)
+	errLabel := widget.NewLabel("")
+	errLabel.Hide()
 
 	docs := container.NewVBox()
-	var ndocs <-chan *store.NamedDocument
-	newViewPageButton := func(ndoc *store.NamedDocument, st store.IDocumentStore) fyne.CanvasObject {
+	facets := container.NewMax()
+	q := query.Query{Orders: []query.Order{store.TimeOrder{FrontNew: true}}}
+	var page *store.Page
+	// stats are loaded off the UI thread. viewMutex orders the updates of page, docs and facets,
+	// and gen, incremented by each new query, drops the results of older ones.
+	var viewMutex sync.Mutex
+	gen := 0
+	newViewPageButton := func(ndoc *store.NamedDocument, st store.IDocumentStore, rs *store.RatingSummary) fyne.CanvasObject {
 		hline := widget.NewRichTextFromMarkdown("-----")
 		btn := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
 			vpage, closer := NewViewerPage(gui, ndoc, st)
 			gui.addPageToTabs(title+"_view_"+ndoc.Title, vpage, closer)
 		})
-		return container.NewBorder(hline, nil, btn, nil, newDocumentCard(ndoc))
+		return container.NewBorder(hline, nil, btn, nil, newDocumentCard(ndoc, rs))
 	}
 	resetDocs := func() {
 		for _, obj := range docs.Objects {
 			docs.Remove(obj)
 		}
 	}
-	loadDocs := func() {
-		N := 10
-		for i := 0; i < N; i++ {
-			ndoc, ok := <-ndocs
-			if ok {
-				docs.Add(newViewPageButton(ndoc, st))
+
+	prevBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), nil)
+	nextBtn := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), nil)
+	pageLabel := widget.NewLabel("")
+	prevBtn.Disable()
+	nextBtn.Disable()
+	loadPage := func(cursor string) error {
+		pageSize := 10
+		p, err := st.QueryPage(cursor, pageSize, q)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, len(p.Docs))
+		for idx, ndoc := range p.Docs {
+			keys[idx] = ndoc.Name
+		}
+		summaries, err := st.RatingSummaries(keys)
+		if err != nil {
+			summaries = nil
+		}
+		viewMutex.Lock()
+		defer viewMutex.Unlock()
+		page = p
+		resetDocs()
+		for _, ndoc := range page.Docs {
+			docs.Add(newViewPageButton(ndoc, st, summaries[strings.TrimPrefix(ndoc.Name, "/")]))
+		}
+		pageLabel.SetText(strconv.Itoa(page.Offset/pageSize + 1))
+		if page.Prev == "" {
+			prevBtn.Disable()
+		} else {
+			prevBtn.Enable()
+		}
+		if page.Next == "" {
+			nextBtn.Disable()
+		} else {
+			nextBtn.Enable()
+		}
+		return nil
+	}
+	prevBtn.OnTapped = func() { loadPage(page.Prev) }
+	nextBtn.OnTapped = func() { loadPage(page.Next) }
+
+	var addFilter func(query.Filter)
+	// setQuery must be called on the UI thread, before loadPage.
+	setQuery := func(newQ query.Query) {
+		viewMutex.Lock()
+		defer viewMutex.Unlock()
+		q = newQ
+		gen++
+	}
+	loadFacets := func() {
+		viewMutex.Lock()
+		qc, g := q, gen
+		viewMutex.Unlock()
+		go func() {
+			stats, err := st.Stats(qc)
+			if err != nil {
+				return
 			}
+			viewMutex.Lock()
+			defer viewMutex.Unlock()
+			if g != gen {
+				return
+			}
+			facets.Objects = []fyne.CanvasObject{newFacetSidebar(stats, addFilter)}
+			facets.Refresh()
+		}()
+	}
+	addFilter = func(f query.Filter) {
+		fs := make([]query.Filter, len(q.Filters), len(q.Filters)+1)
+		copy(fs, q.Filters)
+		newQ := q
+		newQ.Filters = append(fs, f)
+		setQuery(newQ)
+		if err := loadPage(""); err == nil {
+			loadFacets()
 		}
 	}
+	go func() {
+		stats, err := st.Stats()
+		if err != nil {
+			return
+		}
+		viewMutex.Lock()
+		defer viewMutex.Unlock()
+		// the overview is only for the empty page before the first search
+		if page != nil {
+			return
+		}
+		docs.Add(newStoreOverview(stats, addFilter))
+	}()
+
 	searchBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
 		es := strings.Fields(searchEntry.Text)
-		qf := modeToQueryFunc(modeSelector.Selected)
-		q := qf(es...)
-		q.Orders = []query.Order{store.TimeOrder{FrontNew: orderBtn.Selected == order[0]}}
-		var err error
-		ndocs, err = st.Query(q)
+		if modeSelector.Selected == "query" {
+			// keep the spacing so that syntax error columns match the entry
+			es = []string{searchEntry.Text}
+		}
+		qf := modeToQueryFunc(modeSelector.Selected, st)
+		newQ, err := qf(es...)
 		if err != nil {
+			errLabel.SetText(err.Error())
+			errLabel.Show()
+			return
+		}
+		errLabel.Hide()
+		newQ.Orders = append(newQ.Orders, store.TimeOrder{FrontNew: orderBtn.Selected == order[0]})
+		if retractedCheck.Checked {
+			newQ.Filters = append(newQ.Filters, store.IncludeRetracted{})
+		}
+		setQuery(newQ)
+		if err := loadPage(""); err != nil {
 			searchEntry.SetText("")
 			return
 		}
-		resetDocs()
-		loadDocs()
+		loadFacets()
 	})
-	moreBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), loadDocs)
 
-	orderSearch := container.NewHBox(orderBtn, searchBtn)
+	orderSearch := container.NewHBox(retractedCheck, orderBtn, searchBtn)
 	searchObj := container.NewBorder(nil, nil, modeSelector, orderSearch, searchEntry)
-	upObj := container.NewBorder(nil, nil, uploadBtn, nil)
+	upObj := container.NewBorder(nil, nil, container.NewHBox(uploadBtn, pinsBtn), NewArchiveBar(w, st))
 
-	searchBar := container.NewBorder(upObj, nil, nil, nil, searchObj)
-	moreObj := container.NewCenter(moreBtn)
+	searchBar := container.NewBorder(upObj, errLabel, nil, nil, searchObj)
+	pageObj := container.NewCenter(container.NewHBox(prevBtn, pageLabel, nextBtn))
 	docsObj := container.NewMax(container.NewVScroll(docs))
 
-	return container.NewBorder(searchBar, moreObj, nil, nil, docsObj)
+	return container.NewBorder(searchBar, pageObj, facets, nil, docsObj)
 }
 
-type queryFunc func(strs ...string) query.Query
+type queryFunc func(strs ...string) (query.Query, error)
 
-func modeToQueryFunc(mode string) queryFunc {
-	return func(strs ...string) query.Query {
+func modeToQueryFunc(mode string, st store.IDocumentStore) queryFunc {
+	return func(strs ...string) (query.Query, error) {
 		switch mode {
 		case "key (pid/username/docname)":
 			fs := make([]query.Filter, len(strs))
@@ -102,32 +213,47 @@ func modeToQueryFunc(mode string) queryFunc {
 					fs[idx] = crdt.KeyExistFilter{Key: str}
 				}
 			}
-			return query.Query{Filters: fs}
+			return query.Query{Filters: fs}, nil
 		case "title":
 			fs := make([]query.Filter, len(strs))
 			for idx, str := range strs {
 				fs[idx] = store.TitleFilter{Title: str}
 			}
-			return query.Query{Filters: fs}
+			return query.Query{Filters: fs}, nil
 		case "cid":
-			return query.Query{Filters: []query.Filter{store.CidsFilter{Cids: strs}}}
+			return query.Query{Filters: []query.Filter{store.CidsFilter{Cids: strs}}}, nil
 		case "document type":
-			return query.Query{Filters: []query.Filter{store.DocTypesFilter{DocTypes: strs}}}
+			return query.Query{Filters: []query.Filter{store.DocTypesFilter{DocTypes: strs}}}, nil
 		case "tag":
-			return query.Query{Filters: []query.Filter{store.TagsFilter{Tags: strs}}}
+			return query.Query{Filters: []query.Filter{store.TagsFilter{Tags: strs}}}, nil
+		case "full text":
+			o := st.Relevance(strings.Join(strs, " "))
+			return query.Query{Filters: []query.Filter{o}, Orders: []query.Order{o}}, nil
+		case "query":
+			f, err := store.ParseQuery(strings.Join(strs, " "))
+			if err != nil {
+				return query.Query{}, err
+			}
+			return query.Query{Filters: []query.Filter{f}}, nil
 		default:
-			return query.Query{}
+			return query.Query{}, nil
 		}
 	}
 }
 
-func newDocumentCard(ndoc *store.NamedDocument) fyne.CanvasObject {
+func newDocumentCard(ndoc *store.NamedDocument, rs *store.RatingSummary) fyne.CanvasObject {
 	nm := &widget.Label{
 		Text:     ndoc.Name,
 		Wrapping: fyne.TextTruncate,
 	}
 	nm.ExtendBaseWidget(nm)
 
+	author := &widget.Label{
+		Text:     authorText(ndoc),
+		Wrapping: fyne.TextTruncate,
+	}
+	author.ExtendBaseWidget(author)
+
 	ttl := &widget.Label{
 		Text:     ndoc.Title,
 		Wrapping: fyne.TextTruncate,
@@ -148,8 +274,12 @@ func newDocumentCard(ndoc *store.NamedDocument) fyne.CanvasObject {
 	desc.ExtendBaseWidget(desc)
 
 	tps := docTypesToIcons(ndoc.DocTypes)
+	badge := ratingBadge(rs)
+	if ndoc.Retraction != nil {
+		badge = retractionNotice(ndoc.Retraction)
+	}
 
-	return container.NewVBox(ttl, desc, tps, tm, nm)
+	return container.NewVBox(ttl, desc, tps, badge, withIdenticon(signerKey(ndoc), author), tm, nm)
 }
 func extractDescription(desc string, n int) string {
 	if len(desc) <= n {
@@ -29,18 +29,12 @@ func (gui *GUI) NewSetupPage() fyne.CanvasObject {
 	stFunc := newStore(gui, titleEntry, baddrsLabel, storeLabel)
 	storeBtn := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), stFunc)
 
-	userNameEntry := widget.NewEntry()
-	userNameEntry.SetPlaceHolder("user name")
-	uiLabel := gutil.NewCopyButton("user identity")
-	uiBtn := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
-		kp := store.NewKeyPair()
-		ui := store.NewUserIdentity(userNameEntry.Text, kp.Verify(), kp.Sign())
-		uiLabel.SetText(ui.ToString())
+	identitiesBtn := widget.NewButtonWithIcon("identities", theme.AccountIcon(), func() {
+		ipage, closer := gui.NewIdentitiesPage()
+		gui.addPageToTabs("identities", ipage, closer)
 	})
-
 	hline2 := widget.NewRichTextFromMarkdown("-----")
-	uiStr := container.NewBorder(nil, nil, uiBtn, nil, uiLabel.Render())
-	userObj := container.NewVBox(hline2, userNameEntry, uiStr)
+	userObj := container.NewVBox(hline2, identitiesBtn)
 
 	hline := widget.NewRichTextFromMarkdown("-----")
 	baddrs := container.NewBorder(nil, nil, addrsBtn, nil, baddrsLabel.Render())
@@ -88,17 +82,14 @@ func newStore(gui *GUI, te *widget.Entry, bLabel, stLabel *gutil.CopyButton) fun
 
 			stLabel.SetText("processing...")
 			storesKey := "setup"
-			if _, exist := gui.stores[storesKey]; exist {
-				gui.stores[storesKey].Close()
-				gui.stores[storesKey] = nil
-			}
+			gui.stores.Remove(storesKey)
 
 			baseDir := store.BaseDir(filepath.Join("stores", storesKey))
 			st, err := store.NewDocumentStore(te.Text, bLabel.GetText(), baseDir)
 			if err != nil {
 				stLabel.SetText("document store address")
 			} else {
-				gui.stores[storesKey] = st
+				gui.stores.Set(storesKey, st)
 				addrs := strings.Split(st.Address(), "/")
 				addr := strings.Join(addrs[1:], "/")
 				stLabel.SetText(addr)
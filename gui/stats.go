@@ -0,0 +1,127 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	store "github.com/pilinsin/lontan/store"
+)
+
+const nTopFacets = 10
+
+func formatSize(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	s := float64(size)
+	idx := 0
+	for s >= 1024 && idx < len(units)-1 {
+		s /= 1024
+		idx++
+	}
+	if idx == 0 {
+		return strconv.FormatInt(size, 10) + " " + units[idx]
+	}
+	return strconv.FormatFloat(s, 'f', 1, 64) + " " + units[idx]
+}
+
+func storageText(stats *store.StoreStats) string {
+	text := fmt.Sprintf("%d documents, declared size %s", stats.Documents, formatSize(stats.DeclaredSize))
+	if stats.UnknownSizeCids > 0 {
+		text += fmt.Sprintf(" (+%d data of undeclared size)", stats.UnknownSizeCids)
+	}
+	return text
+}
+
+func newFacetButtons(fcs []store.FacetCount, onTapped func(string)) fyne.CanvasObject {
+	btns := container.NewVBox()
+	for _, fc := range fcs {
+		value := fc.Value
+		btn := widget.NewButton(fmt.Sprintf("%s (%d)", value, fc.Count), func() {
+			onTapped(value)
+		})
+		btn.Alignment = widget.ButtonAlignLeading
+		btns.Add(btn)
+	}
+	return btns
+}
+
+// newHistogram shows the number of documents per month as bars.
+func newHistogram(months []store.FacetCount, onTapped func(string)) fyne.CanvasObject {
+	max := 0
+	for _, fc := range months {
+		if fc.Count > max {
+			max = fc.Count
+		}
+	}
+
+	rows := container.NewVBox()
+	for _, fc := range months {
+		month, count := fc.Value, fc.Count
+		btn := widget.NewButton(month, func() {
+			onTapped(month)
+		})
+		bar := widget.NewProgressBar()
+		bar.Max = float64(max)
+		bar.TextFormatter = func() string { return strconv.Itoa(count) }
+		bar.SetValue(float64(count))
+		rows.Add(container.NewBorder(nil, nil, btn, nil, bar))
+	}
+	return rows
+}
+
+// facet values are turned into filters by onFilter.
+type facetFilters struct {
+	onFilter func(query.Filter)
+}
+
+func (ff facetFilters) tag(tag string) { ff.onFilter(store.TagsFilter{Tags: []string{tag}}) }
+func (ff facetFilters) docType(docType string) {
+	ff.onFilter(store.DocTypesFilter{DocTypes: []string{docType}})
+}
+func (ff facetFilters) author(author string) { ff.onFilter(store.AuthorFilter{Author: author}) }
+func (ff facetFilters) month(month string) {
+	f, err := store.MonthFilter(month)
+	if err == nil {
+		ff.onFilter(f)
+	}
+}
+
+// newFacetSidebar shows the counts of the search results. Tapping a value narrows the search.
+func newFacetSidebar(stats *store.StoreStats, onFilter func(query.Filter)) fyne.CanvasObject {
+	ff := facetFilters{onFilter}
+	total := widget.NewLabel(storageText(stats))
+	total.Wrapping = fyne.TextWrapWord
+
+	acc := widget.NewAccordion(
+		widget.NewAccordionItem("tag", newFacetButtons(store.TopCounts(stats.Tags, nTopFacets), ff.tag)),
+		widget.NewAccordionItem("document type", newFacetButtons(store.TopCounts(stats.DocTypes, 0), ff.docType)),
+		widget.NewAccordionItem("author", newFacetButtons(store.TopCounts(stats.Authors, nTopFacets), ff.author)),
+		widget.NewAccordionItem("month", newHistogram(store.SortedMonths(stats.Months), ff.month)),
+	)
+	acc.MultiOpen = true
+	acc.Open(0)
+	return container.NewVScroll(container.NewVBox(total, acc))
+}
+
+// newStoreOverview is shown before the first search.
+func newStoreOverview(stats *store.StoreStats, onFilter func(query.Filter)) fyne.CanvasObject {
+	ff := facetFilters{onFilter}
+	total := widget.NewLabelWithStyle(storageText(stats), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	tagsTitle := widget.NewLabel("top tags")
+	tags := container.NewGridWithColumns(4)
+	for _, fc := range store.TopCounts(stats.Tags, nTopFacets*2) {
+		tag := fc.Value
+		tags.Add(widget.NewButton(fmt.Sprintf("%s (%d)", tag, fc.Count), func() {
+			ff.tag(tag)
+		}))
+	}
+	histTitle := widget.NewLabel("documents per month")
+	hist := newHistogram(store.SortedMonths(stats.Months), ff.month)
+
+	return container.NewVBox(total, tagsTitle, tags, histTitle, hist)
+}
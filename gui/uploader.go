@@ -67,11 +67,10 @@ func uplpadDialog(w fyne.Window, label iText, is ipfs.Ipfs, ext string, ub *uplo
 }
 
 //dialog
-func NewUploadPage(w fyne.Window, st store.IDocumentStore) fyne.CanvasObject {
+func NewUploadPage(w fyne.Window, st store.IDocumentStore, kr *keyring) (fyne.CanvasObject, gutil.Closer) {
 	noteLabel := widget.NewLabel("upload file")
 
-	ui := widget.NewEntry()
-	ui.SetPlaceHolder("user identity")
+	uiObj, selectedIdentity, closer := newIdentitySelector(kr, st, noteLabel)
 	name := widget.NewEntry()
 	name.SetPlaceHolder("document name: <pid/username/docname>")
 	title := widget.NewEntry()
@@ -118,14 +117,8 @@ func NewUploadPage(w fyne.Window, st store.IDocumentStore) fyne.CanvasObject {
 			return
 		}
 
-		uid := &store.UserIdentity{}
-		if err := uid.FromString(ui.Text); err != nil {
-			uid = nil
-		}
-		st.SetUserIdentity(uid)
-
 		docInfo := store.NewDocumentInfo(title.Text, description.Text, sliceToMap(docTypes), sliceToMap(tags.Texts()), time.Now().UTC())
-		if err := st.Put(name.Text, docInfo, tds...); err != nil {
+		if err := st.PutAs(selectedIdentity(), name.Text, docInfo, tds...); err != nil {
 			noteLabel.SetText(fmt.Sprintln("upload error", err))
 		} else {
 			noteLabel.SetText("uploaded")
@@ -133,8 +126,8 @@ func NewUploadPage(w fyne.Window, st store.IDocumentStore) fyne.CanvasObject {
 	})
 
 	upBtnLabel := container.NewBorder(nil, nil, uploadBtn, nil, noteLabel)
-	page := container.NewVBox(ui, name, title, description, tags.Render(), btns, dataObjs, upBtnLabel)
-	return container.NewMax(container.NewVScroll(page))
+	page := container.NewVBox(uiObj, name, title, description, tags.Render(), btns, dataObjs, upBtnLabel)
+	return container.NewMax(container.NewVScroll(page)), closer
 }
 
 func isValidDocumentInfo(title, desc string) bool {
@@ -1,6 +1,8 @@
 package gui
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -54,18 +56,113 @@ func descriptionLabel(text string) fyne.CanvasObject {
 	return lbl
 }
 
+// authorText is the username with the fingerprint of the signer,
+// which tells apart users of the same name.
+func authorText(ndoc *store.NamedDocument) string {
+	keys := strings.Split(strings.TrimPrefix(ndoc.Name, "/"), "/")
+	if len(keys) != 3 {
+		return ""
+	}
+	if ndoc.Signer == nil {
+		return keys[1] + " [unknown signer]"
+	}
+	text := keys[1] + " [" + ndoc.Signer.Fingerprint + "]"
+	if !ndoc.Signer.Valid {
+		text += " invalid signature"
+	}
+	return text
+}
+
+func revisionName(ndoc *store.NamedDocument) string {
+	return fmt.Sprintf("revision %d (%s)", ndoc.Revision, ndoc.Time.String())
+}
+func newHistorySelector(gui *GUI, nmDoc *store.NamedDocument, st store.IDocumentStore) fyne.CanvasObject {
+	revs, err := st.Revisions(nmDoc.Name)
+	if err != nil || len(revs) == 0 {
+		return descriptionLabel(revisionName(nmDoc))
+	}
+
+	names := make([]string, len(revs))
+	revMap := make(map[string]*store.NamedDocument, len(revs))
+	for idx, rev := range revs {
+		names[idx] = revisionName(rev)
+		revMap[names[idx]] = rev
+	}
+	selector := widget.NewSelect(names, nil)
+	selector.Selected = revisionName(nmDoc)
+	selector.OnChanged = func(name string) {
+		rev, ok := revMap[name]
+		if !ok || rev.Revision == nmDoc.Revision {
+			return
+		}
+		vpage, closer := NewViewerPage(gui, rev, st)
+		gui.addPageToTabs(rev.Title+"_rev_"+strconv.FormatInt(rev.Revision, 10), vpage, closer)
+		selector.SetSelected(revisionName(nmDoc))
+	}
+	changes := widget.NewButton("changes", func() {
+		gui.addPageToTabs(nmDoc.Title+"_changes", newChangesPage(revs))
+	})
+	return container.NewBorder(nil, nil, widget.NewLabel("history"), changes, selector)
+}
+
+// newChangesPage lists the changed fields of each revision from the previous one.
+// revs are from newer to older, as st.Revisions returns them.
+func newChangesPage(revs []*store.NamedDocument) fyne.CanvasObject {
+	objs := make([]fyne.CanvasObject, 0)
+	for idx := 0; idx+1 < len(revs); idx++ {
+		objs = append(objs, widget.NewLabelWithStyle(revisionName(revs[idx]), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		diffs := store.DiffRevisions(revs[idx+1].Document, revs[idx].Document)
+		if len(diffs) == 0 {
+			objs = append(objs, descriptionLabel("no changes"))
+		}
+		for _, diff := range diffs {
+			for _, v := range diff.Removed {
+				objs = append(objs, descriptionLabel(diff.Field+" - "+v))
+			}
+			for _, v := range diff.Added {
+				objs = append(objs, descriptionLabel(diff.Field+" + "+v))
+			}
+		}
+	}
+	if len(objs) == 0 {
+		return container.NewCenter(widget.NewLabel("no earlier revision"))
+	}
+	return container.NewMax(container.NewVScroll(container.NewVBox(objs...)))
+}
+
 func NewViewerPage(gui *GUI, nmDoc *store.NamedDocument, st store.IDocumentStore) (fyne.CanvasObject, gutil.Closer) {
 	if nmDoc == nil {
 		return container.NewCenter(widget.NewLabel("no document")), nil
 	}
 
-	medias := make([]fyne.CanvasObject, len(nmDoc.Cids))
+	medias := make([]fyne.CanvasObject, 0, len(nmDoc.Cids))
 	closers := make([]gutil.Closer, 0)
-	for idx, cid := range nmDoc.Cids {
-		media, closer := loadMedia(gui, cid.Type, cid.Cid, st.Ipfs())
-		medias[idx] = media
-		if closer != nil {
-			closers = append(closers, closer)
+	if nmDoc.Retraction != nil {
+		medias = append(medias, retractionNotice(nmDoc.Retraction))
+	} else {
+		for _, cid := range nmDoc.Cids {
+			media, closer := loadMedia(gui, cid.Type, cid.Cid, st.Ipfs())
+			medias = append(medias, media)
+			if closer != nil {
+				closers = append(closers, closer)
+			}
+		}
+	}
+	name := descriptionLabel(nmDoc.Name)
+	author := withIdenticon(signerKey(nmDoc), descriptionLabel(authorText(nmDoc)))
+	title := descriptionLabel(nmDoc.Title)
+	tm := descriptionLabel(nmDoc.Time.String())
+	dTypes := docTypesToIcons(nmDoc.DocTypes)
+	tags := tagsLabel(nmDoc.Tags)
+	description := descriptionLabel(nmDoc.Description)
+
+	history := newHistorySelector(gui, nmDoc, st)
+	rating, ratingCloser := NewRatingPane(st, nmDoc.Name, gui.keys)
+	retract, retractCloser := NewRetractForm(st, nmDoc, gui.keys)
+	comment, commentCloser := NewCommentPane(st, nmDoc.Name, gui.keys)
+	for _, c := range []gutil.Closer{ratingCloser, retractCloser, commentCloser} {
+		if c != nil {
+			closers = append(closers, c)
 		}
 	}
 	closer := func() error {
@@ -78,16 +175,11 @@ func NewViewerPage(gui *GUI, nmDoc *store.NamedDocument, st store.IDocumentStore
 		return err
 	}
 
-	name := descriptionLabel(nmDoc.Name)
-	title := descriptionLabel(nmDoc.Title)
-	tm := descriptionLabel(nmDoc.Time.String())
-	dTypes := docTypesToIcons(nmDoc.DocTypes)
-	tags := tagsLabel(nmDoc.Tags)
-	description := descriptionLabel(nmDoc.Description)
-
 	objs := make([]fyne.CanvasObject, 0)
-	objs = append(objs, title, name, tm, dTypes, tags, description)
+	objs = append(objs, title, author, name, tm, history, dTypes, tags, description, NewPinButton(st, nmDoc.Name), rating, retract)
 	objs = append(objs, medias...)
+	hline := widget.NewRichTextFromMarkdown("-----")
+	objs = append(objs, hline, comment)
 	page := container.NewVBox(objs...)
 	return container.NewMax(container.NewVScroll(page)), closer
 }
@@ -0,0 +1,340 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+const archiveFetchTimeout = time.Minute
+
+// An archive is a zip file of
+// manifest: pb.Manifest,
+// entries/<n>: pb.ArchiveEntry (a signed entry),
+// data/<cid>: the data of the cid.
+const manifestPath = "manifest"
+
+type ArchiveReport struct {
+	Entries int
+	Data    int
+	// keys or cids which could not be exported or imported, with the reason
+	Failed []string
+}
+
+func (r *ArchiveReport) fail(item string, err error) {
+	r.Failed = append(r.Failed, item+": "+err.Error())
+}
+
+func (se *SignedEntry) marshal() []byte {
+	mse := &pb.ArchiveEntry{
+		Key:   se.Key,
+		Value: se.Value,
+		Sign:  se.Sign,
+	}
+	m, _ := proto.Marshal(mse)
+	return m
+}
+func unmarshalSignedEntry(m []byte) (*SignedEntry, error) {
+	mse := &pb.ArchiveEntry{}
+	if err := proto.Unmarshal(m, mse); err != nil {
+		return nil, err
+	}
+	return &SignedEntry{mse.GetKey(), mse.GetValue(), mse.GetSign()}, nil
+}
+
+// entryCids adds the cids referenced by a document or a revision entry, including the cid of the previous revision.
+func entryCids(key string, value []byte, cids map[string]struct{}) {
+	if _, _, ok := revisionDocKey(key); !ok && len(splitKey(key)) != 3 {
+		return
+	}
+	doc := newEmptyDocument()
+	if err := doc.Unmarshal(value); err != nil {
+		return
+	}
+	for _, tc := range doc.Cids {
+		cids[tc.Cid] = struct{}{}
+	}
+	if doc.Prev != "" {
+		cids[doc.Prev] = struct{}{}
+	}
+}
+
+// referencedCids returns the cids of all document revisions.
+func referencedCids(ses []*SignedEntry) []string {
+	cids := make(map[string]struct{})
+	for _, se := range ses {
+		entryCids(se.Key, se.Value, cids)
+	}
+	return mapToSlice(cids)
+}
+
+// manifestIndex lists what the other side already has.
+// A nil manifestIndex has nothing.
+type manifestIndex struct {
+	entries map[string]struct{}
+	data    map[string]struct{}
+}
+
+func newManifestIndex(mm *pb.Manifest) *manifestIndex {
+	mi := &manifestIndex{
+		entries: make(map[string]struct{}, len(mm.GetEntries())),
+		data:    make(map[string]struct{}, len(mm.GetData())),
+	}
+	for _, me := range mm.GetEntries() {
+		mi.entries[me.GetKey()] = struct{}{}
+	}
+	for _, md := range mm.GetData() {
+		mi.data[md.GetCid()] = struct{}{}
+	}
+	return mi
+}
+func (mi *manifestIndex) hasEntry(key string) bool {
+	if mi == nil {
+		return false
+	}
+	_, ok := mi.entries[key]
+	return ok
+}
+func (mi *manifestIndex) hasData(cid string) bool {
+	if mi == nil {
+		return false
+	}
+	_, ok := mi.data[cid]
+	return ok
+}
+
+// ExportArchive writes every signed entry of the store and the data referenced by documents.
+func (ds *documentStore) ExportArchive(w io.Writer) (*ArchiveReport, error) {
+	return ds.exportArchive(w, nil)
+}
+
+// ExportDelta writes what the store has and the other side does not.
+// known is an archive or a manifest (see ExportManifest) made on the other side.
+func (ds *documentStore) ExportDelta(w io.Writer, known io.ReaderAt, size int64) (*ArchiveReport, error) {
+	zr, err := zip.NewReader(known, size)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := readManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+	return ds.exportArchive(w, newManifestIndex(mm))
+}
+
+func (ds *documentStore) exportArchive(w io.Writer, known *manifestIndex) (*ArchiveReport, error) {
+	ses, err := ds.signedEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	zw := zip.NewWriter(w)
+	report := &ArchiveReport{}
+	mt, _ := time.Now().UTC().MarshalBinary()
+	mm := &pb.Manifest{
+		Address: ds.addr,
+		Time:    mt,
+	}
+
+	for _, se := range ses {
+		if known.hasEntry(se.Key) {
+			continue
+		}
+		m := se.marshal()
+		path := "entries/" + strconv.Itoa(len(mm.Entries))
+		f, err := zw.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(m); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(m)
+		mm.Entries = append(mm.Entries, &pb.ManifestEntry{
+			Key:  se.Key,
+			Path: path,
+			Hash: sum[:],
+		})
+	}
+	report.Entries = len(mm.Entries)
+
+	for _, cid := range referencedCids(ses) {
+		if known.hasData(cid) {
+			continue
+		}
+		r, err := ds.is.GetReader(cid, archiveFetchTimeout)
+		if err != nil {
+			report.fail(cid, err)
+			continue
+		}
+		path := "data/" + cid
+		f, err := zw.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+			return nil, err
+		}
+		mm.Data = append(mm.Data, &pb.ManifestData{
+			Cid:  cid,
+			Path: path,
+			Hash: h.Sum(nil),
+		})
+	}
+	report.Data = len(mm.Data)
+
+	if err := writeManifest(zw, mm); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func writeManifest(zw *zip.Writer, mm *pb.Manifest) error {
+	m, err := proto.Marshal(mm)
+	if err != nil {
+		return err
+	}
+	f, err := zw.Create(manifestPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(m); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ExportManifest writes an archive with only the manifest of the store,
+// which lists the entries and the locally available data without their contents.
+// The other side passes it to ExportDelta.
+func (ds *documentStore) ExportManifest(w io.Writer) error {
+	ses, err := ds.signedEntries()
+	if err != nil {
+		return err
+	}
+
+	mt, _ := time.Now().UTC().MarshalBinary()
+	mm := &pb.Manifest{
+		Address: ds.addr,
+		Time:    mt,
+	}
+	for _, se := range ses {
+		sum := sha256.Sum256(se.marshal())
+		mm.Entries = append(mm.Entries, &pb.ManifestEntry{
+			Key:  se.Key,
+			Hash: sum[:],
+		})
+	}
+	for _, cid := range referencedCids(ses) {
+		if has, err := ds.is.Has(cid, time.Second); err != nil || !has {
+			continue
+		}
+		mm.Data = append(mm.Data, &pb.ManifestData{Cid: cid})
+	}
+	return writeManifest(zip.NewWriter(w), mm)
+}
+
+func readZipFile(zr *zip.Reader, path string, hash []byte) ([]byte, error) {
+	f, err := zr.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if hash != nil {
+		sum := sha256.Sum256(m)
+		if !bytes.Equal(sum[:], hash) {
+			return nil, errors.New("hash mismatch")
+		}
+	}
+	return m, nil
+}
+
+func readManifest(zr *zip.Reader) (*pb.Manifest, error) {
+	m, err := readZipFile(zr, manifestPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	mm := &pb.Manifest{}
+	if err := proto.Unmarshal(m, mm); err != nil {
+		return nil, err
+	}
+	return mm, nil
+}
+
+// ImportArchive checks the hashes and the signatures in the archive and loads it into the store.
+// A delta archive is merged in the same way.
+// Entries which already exist in the store are kept as they are, as the signature store never overwrites a key.
+func (ds *documentStore) ImportArchive(r io.ReaderAt, size int64) (*ArchiveReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := readManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	// items without path are only listed (a manifest made by ExportManifest)
+	report := &ArchiveReport{}
+	for _, md := range mm.GetData() {
+		if md.GetPath() == "" {
+			continue
+		}
+		m, err := readZipFile(zr, md.GetPath(), md.GetHash())
+		if err != nil {
+			report.fail(md.GetCid(), err)
+			continue
+		}
+		cid, err := ds.Ipfs().Add(m, archiveFetchTimeout)
+		if err != nil {
+			report.fail(md.GetCid(), err)
+			continue
+		}
+		if cid != md.GetCid() {
+			report.fail(md.GetCid(), errors.New("cid mismatch"))
+			continue
+		}
+		report.Data++
+	}
+
+	for _, me := range mm.GetEntries() {
+		if me.GetPath() == "" {
+			continue
+		}
+		m, err := readZipFile(zr, me.GetPath(), me.GetHash())
+		if err != nil {
+			report.fail(me.GetKey(), err)
+			continue
+		}
+		se, err := unmarshalSignedEntry(m)
+		if err != nil {
+			report.fail(me.GetKey(), err)
+			continue
+		}
+		if se.Key != me.GetKey() {
+			report.fail(me.GetKey(), errors.New("key mismatch"))
+			continue
+		}
+		if err := ds.putSigned(se); err != nil {
+			report.fail(me.GetKey(), err)
+			continue
+		}
+		report.Entries++
+	}
+
+	go ds.watcher.poll()
+	return report, nil
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func putTestDocument(t *testing.T, ds *documentStore, ui *UserIdentity, name, title, text string) string {
+	info := NewDocumentInfo(title, "description", nil, nil, time.Now())
+	if err := ds.PutAs(ui, name, info, NewTypedData("text", strings.NewReader(text))); err != nil {
+		t.Fatal(err)
+	}
+	return pid(ui) + "/" + ui.userName + "/" + name
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	b := newTestBootstrap(t)
+	src := newTestDocumentStore(t, b, "src")
+	dst := newTestDocumentStore(t, b, "dst")
+	ui := newTestIdentity("alice")
+	key := putTestDocument(t, src, ui, "doc", "first", "first text")
+	putTestDocument(t, src, ui, "doc", "second", "second text")
+
+	buf := &bytes.Buffer{}
+	report, err := src.ExportArchive(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Failed) > 0 || report.Entries == 0 || report.Data == 0 {
+		t.Fatalf("export: %+v", report)
+	}
+
+	ireport, err := dst.ImportArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ireport.Failed) > 0 || ireport.Entries != report.Entries || ireport.Data != report.Data {
+		t.Fatalf("import: %+v, want the %d entries and %d data of the export", ireport, report.Entries, report.Data)
+	}
+
+	ndoc, err := dst.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ndoc.Title != "second" {
+		t.Errorf("title %q, want second", ndoc.Title)
+	}
+	if ndoc.Signer == nil || !ndoc.Signer.Valid || ndoc.Signer.Fingerprint != ui.Fingerprint() {
+		t.Errorf("signer %+v, want a valid one of %s", ndoc.Signer, ui.Fingerprint())
+	}
+	ndocs, err := dst.Revisions(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ndocs) != 2 {
+		t.Fatalf("%d revisions, want 2", len(ndocs))
+	}
+	for _, nd := range ndocs {
+		m, err := dst.Ipfs().Get(nd.Cids[0].Cid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := nd.Title + " text"; string(m) != want {
+			t.Errorf("data %q, want %q", m, want)
+		}
+	}
+
+	// nothing is left to send once both sides have the same
+	mbuf := &bytes.Buffer{}
+	if err := dst.ExportManifest(mbuf); err != nil {
+		t.Fatal(err)
+	}
+	dreport, err := src.ExportDelta(&bytes.Buffer{}, bytes.NewReader(mbuf.Bytes()), int64(mbuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dreport.Failed) > 0 || dreport.Entries != 0 || dreport.Data != 0 {
+		t.Errorf("delta: %+v, want nothing", dreport)
+	}
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"encoding/base64"
 	"os"
 	"path/filepath"
+
+	"golang.org/x/crypto/argon2"
 )
 
 func exeDir() string {
@@ -16,3 +19,31 @@ func exeDir() string {
 func BaseDir(addr string) string {
 	return filepath.Join(exeDir(), addr)
 }
+
+// StoreHash names the local directory of a loaded store.
+// stAddr is the store address without the bootstrap list address and the title.
+func StoreHash(title, stAddr string) string {
+	b := argon2.IDKey([]byte(stAddr), []byte(title), 1, 64*1024, 4, 64)
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+// StoreDir returns the local directory of the store at addr (bootstrap list address/title/store address).
+func StoreDir(addr string) (string, error) {
+	key, err := StoreKey(addr)
+	if err != nil {
+		return "", err
+	}
+	return BaseDir(filepath.Join("stores", key)), nil
+}
+
+// writeFile replaces the file at once so that a crash never leaves it half written.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"io"
+
+	proto "google.golang.org/protobuf/proto"
+
+	blockservice "github.com/ipfs/go-blockservice"
+	cid "github.com/ipfs/go-cid"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	ipld "github.com/ipfs/go-ipld-format"
+	merkledag "github.com/ipfs/go-merkledag"
+	ufsio "github.com/ipfs/go-unixfs/io"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+	ipfspb "github.com/pilinsin/p2p-verse/ipfs/pb"
+)
+
+// localBlocks gives access to the blocks kept by the local ipfs store.
+// ipfs.Ipfs cannot list, measure or delete blocks,
+// so the blockstore exposed by the ipfs store of p2p-verse is used.
+type localBlocks struct {
+	bs  blockstore.Blockstore
+	dag ipld.DAGService
+}
+
+type blockStorer interface {
+	BlockStore() blockstore.Blockstore
+}
+
+func newLocalBlocks(is ipfs.Ipfs) (*localBlocks, error) {
+	if bsr, ok := is.(blockStorer); ok {
+		return newLocalBlocksFromStore(bsr.BlockStore()), nil
+	}
+	bs, err := embeddedBlockStore(is)
+	if err != nil {
+		return nil, err
+	}
+	return newLocalBlocksFromStore(bs), nil
+}
+
+func newLocalBlocksFromStore(bs blockstore.Blockstore) *localBlocks {
+	// never fetch missing blocks from the network
+	dag := merkledag.NewDAGService(blockservice.New(bs, offline.Exchange(bs)))
+	return &localBlocks{bs, dag}
+}
+
+// blockKey is the cid of a block as the blockstore lists it.
+// The blockstore is keyed by multihash, so the listed cids are raw CIDv1
+// whatever the codec of the links was.
+func blockKey(c cid.Cid) cid.Cid {
+	return cid.NewCidV1(cid.Raw, c.Hash())
+}
+
+// walk adds the local blocks of the DAG under c to blocks.
+// Missing blocks are skipped, and it returns false if any block is missing,
+// since local blocks below a missing one cannot be found.
+func (lb *localBlocks) walk(ctx context.Context, c cid.Cid, blocks map[cid.Cid]struct{}) (ipld.Node, bool) {
+	nd, err := lb.dag.Get(ctx, c)
+	if err != nil {
+		return nil, false
+	}
+	key := blockKey(c)
+	if _, ok := blocks[key]; ok {
+		return nd, true
+	}
+	blocks[key] = struct{}{}
+	complete := true
+	for _, link := range nd.Links() {
+		if _, ok := lb.walk(ctx, link.Cid, blocks); !ok {
+			complete = false
+		}
+	}
+	return nd, complete
+}
+
+// dataBlocks adds the local blocks of the data added by ipfs.Ipfs to blocks.
+// The data is a file listing the cids of 256KiB files, each of them a DAG of blocks.
+// found is false if the first block of the data is not local. Blocks are fetched from the top,
+// so nothing below it is local then, except blocks shared with other data.
+// complete is false if some blocks are missing, which may hide local blocks below them.
+func (lb *localBlocks) dataBlocks(ctx context.Context, cidStr string, blocks map[cid.Cid]struct{}) (found, complete bool) {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		// an invalid cid has no local blocks
+		return false, true
+	}
+	nd, ok := lb.walk(ctx, c, blocks)
+	if nd == nil {
+		return false, false
+	}
+	if !ok {
+		// the list of the 256KiB files cannot be read
+		return true, false
+	}
+
+	r, err := ufsio.NewDagReader(ctx, nd, lb.dag)
+	if err != nil {
+		return true, false
+	}
+	m, err := io.ReadAll(r)
+	if err != nil {
+		return true, false
+	}
+	bc := &ipfspb.BlockCids{}
+	if err := proto.Unmarshal(m, bc); err != nil {
+		return true, false
+	}
+	complete = true
+	for _, bcStr := range bc.GetCids() {
+		bcid, err := cid.Decode(bcStr)
+		if err != nil {
+			continue
+		}
+		if _, ok := lb.walk(ctx, bcid, blocks); !ok {
+			complete = false
+		}
+	}
+	return true, complete
+}
+
+func (lb *localBlocks) size(ctx context.Context, blocks map[cid.Cid]struct{}) int64 {
+	total := int64(0)
+	for c := range blocks {
+		if n, err := lb.bs.GetSize(ctx, c); err == nil {
+			total += int64(n)
+		}
+	}
+	return total
+}
+
+// all returns every local block with its size.
+func (lb *localBlocks) all(ctx context.Context) (map[cid.Cid]int64, error) {
+	ch, err := lb.bs.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[cid.Cid]int64)
+	for c := range ch {
+		if n, err := lb.bs.GetSize(ctx, c); err == nil {
+			sizes[c] = int64(n)
+		}
+	}
+	return sizes, nil
+}
+
+func (lb *localBlocks) remove(ctx context.Context, c cid.Cid) error {
+	return lb.bs.DeleteBlock(ctx, c)
+}
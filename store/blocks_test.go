@@ -0,0 +1,235 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	cid "github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	chunker "github.com/ipfs/go-ipfs-chunker"
+	importer "github.com/ipfs/go-unixfs/importer"
+	proto "google.golang.org/protobuf/proto"
+
+	pv "github.com/pilinsin/p2p-verse"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+	ipfspb "github.com/pilinsin/p2p-verse/ipfs/pb"
+)
+
+func addTestFile(t *testing.T, lb *localBlocks, m []byte) cid.Cid {
+	nd, err := importer.BuildDagFromReader(lb.dag, chunker.NewSizeSplitter(bytes.NewReader(m), 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nd.Cid()
+}
+
+// addTestData adds data in the layout of ipfs.Ipfs: a file listing the cids of the part files.
+func addTestData(t *testing.T, lb *localBlocks, parts int) (string, []cid.Cid) {
+	rng := rand.New(rand.NewSource(int64(parts)))
+	partCids := make([]cid.Cid, parts)
+	bc := &ipfspb.BlockCids{}
+	for idx := range partCids {
+		m := make([]byte, 4096)
+		rng.Read(m)
+		partCids[idx] = addTestFile(t, lb, m)
+		bc.Cids = append(bc.Cids, partCids[idx].String())
+	}
+	mbc, err := proto.Marshal(bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addTestFile(t, lb, mbc).String(), partCids
+}
+
+func newTestLocalBlocks() *localBlocks {
+	return newLocalBlocksFromStore(blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore())))
+}
+
+func newTestIpfsStore(t *testing.T, b pv.IBootstrap, name string) ipfs.Ipfs {
+	is, err := ipfs.NewIpfsStore(pv.SampleHost, filepath.Join(t.TempDir(), name), false, b.AddrInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(is.Close)
+	return is
+}
+
+func TestIpfsStoreBlocks(t *testing.T) {
+	ctx := context.Background()
+	is := newTestIpfsStore(t, newTestBootstrap(t), "ipfs")
+	m := make([]byte, 1<<19)
+	rand.New(rand.NewSource(1)).Read(m)
+	root, err := is.Add(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lb, err := newLocalBlocks(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+	marked := make(map[cid.Cid]struct{})
+	found, complete := lb.dataBlocks(ctx, root, marked)
+	if !found || !complete {
+		t.Fatalf("found %v, complete %v, want true, true", found, complete)
+	}
+	if size := lb.size(ctx, marked); size < int64(len(m)) {
+		t.Errorf("size %d, want at least %d", size, len(m))
+	}
+	all, err := lb.all(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c := range marked {
+		if _, ok := all[c]; !ok {
+			t.Errorf("block %s is not listed", c)
+		}
+	}
+}
+
+func TestDataBlocksComplete(t *testing.T) {
+	ctx := context.Background()
+	lb := newTestLocalBlocks()
+	root, _ := addTestData(t, lb, 2)
+
+	marked := make(map[cid.Cid]struct{})
+	found, complete := lb.dataBlocks(ctx, root, marked)
+	if !found || !complete {
+		t.Fatalf("found %v, complete %v, want true, true", found, complete)
+	}
+	all, err := lb.all(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marked) != len(all) {
+		t.Errorf("marked %d blocks, want all %d", len(marked), len(all))
+	}
+}
+
+func TestDataBlocksPartlyLocal(t *testing.T) {
+	ctx := context.Background()
+	lb := newTestLocalBlocks()
+	root, parts := addTestData(t, lb, 3)
+
+	// a missing leaf of the first part, as in a partly fetched document
+	first, err := lb.dag.Get(ctx, parts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Links()) < 2 {
+		t.Fatal("the part has no leaves")
+	}
+	if err := lb.remove(ctx, first.Links()[0].Cid); err != nil {
+		t.Fatal(err)
+	}
+
+	marked := make(map[cid.Cid]struct{})
+	found, complete := lb.dataBlocks(ctx, root, marked)
+	if !found || complete {
+		t.Fatalf("found %v, complete %v, want true, false", found, complete)
+	}
+	// every block which is still local is reachable here, so all of them are marked
+	all, err := lb.all(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c := range all {
+		if _, ok := marked[c]; !ok {
+			t.Errorf("local block %s is not marked", c)
+		}
+	}
+	for _, part := range parts[1:] {
+		if _, ok := marked[blockKey(part)]; !ok {
+			t.Errorf("part %s after the missing block is not marked", part)
+		}
+	}
+}
+
+func TestDataBlocksMissingList(t *testing.T) {
+	ctx := context.Background()
+	lb := newTestLocalBlocks()
+	root, _ := addTestData(t, lb, 2)
+
+	c, err := cid.Decode(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lb.remove(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	marked := make(map[cid.Cid]struct{})
+	found, _ := lb.dataBlocks(ctx, root, marked)
+	if found {
+		t.Error("found data without its first block")
+	}
+	if len(marked) != 0 {
+		t.Errorf("marked %d blocks without the list of parts", len(marked))
+	}
+}
+
+func TestSweepPartlyLocal(t *testing.T) {
+	ctx := context.Background()
+	lb := newTestLocalBlocks()
+	complete, _ := addTestData(t, lb, 1)
+	partly, parts := addTestData(t, lb, 2)
+	orphan, _ := addTestData(t, lb, 3)
+
+	first, err := lb.dag.Get(ctx, parts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lb.remove(ctx, first.Links()[0].Cid); err != nil {
+		t.Fatal(err)
+	}
+
+	marked := make(map[cid.Cid]struct{})
+	incomplete := make([]string, 0)
+	for _, root := range []string{complete, partly} {
+		if found, ok := lb.dataBlocks(ctx, root, marked); found && !ok {
+			incomplete = append(incomplete, root)
+		}
+	}
+	if len(incomplete) != 1 || incomplete[0] != partly {
+		t.Fatalf("incomplete %v, want [%s]", incomplete, partly)
+	}
+	before, err := lb.all(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// as GC does, nothing is removed while some data is partly local
+	report := &GCReport{DryRun: len(incomplete) > 0, Incomplete: incomplete}
+	if err := lb.sweep(ctx, marked, report); err != nil {
+		t.Fatal(err)
+	}
+	after, err := lb.all(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("%d blocks removed with partly local data", len(before)-len(after))
+	}
+	if report.Blocks == 0 {
+		t.Error("the orphan blocks are not counted")
+	}
+
+	// without it, only the orphan data is removed
+	report = &GCReport{}
+	if err := lb.sweep(ctx, marked, report); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := lb.dataBlocks(ctx, orphan, make(map[cid.Cid]struct{})); found {
+		t.Error("orphan data is not removed")
+	}
+	for _, root := range []string{complete, partly} {
+		if found, _ := lb.dataBlocks(ctx, root, make(map[cid.Cid]struct{})); !found {
+			t.Errorf("marked data %s is removed", root)
+		}
+	}
+}
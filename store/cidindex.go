@@ -0,0 +1,103 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+// cidIndex maps the cids referenced by document revisions to the types recorded for them.
+// It is kept in memory, since it is rebuilt from the signature store at each start.
+type cidIndex struct {
+	mutex sync.RWMutex
+	// cid -> document key -> type
+	cids      map[string]map[string]string
+	retracted map[string]struct{}
+}
+
+func newCidIndex() *cidIndex {
+	return &cidIndex{
+		cids:      make(map[string]map[string]string),
+		retracted: make(map[string]struct{}),
+	}
+}
+
+func (ci *cidIndex) put(e query.Entry) {
+	key := strings.TrimPrefix(e.Key, "/")
+	if docKey, ok := retractionDocKey(key); ok {
+		ci.mutex.Lock()
+		defer ci.mutex.Unlock()
+		ci.retracted[docKey] = struct{}{}
+		return
+	}
+
+	docKey := key
+	if dk, _, ok := revisionDocKey(key); ok {
+		docKey = dk
+	} else if len(splitKey(key)) != 3 {
+		return
+	}
+	doc := newEmptyDocument()
+	if err := doc.Unmarshal(e.Value); err != nil {
+		return
+	}
+
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	for _, tc := range doc.Cids {
+		if _, ok := ci.cids[tc.Cid]; !ok {
+			ci.cids[tc.Cid] = make(map[string]string)
+		}
+		ci.cids[tc.Cid][docKey] = tc.Type
+	}
+}
+func (ci *cidIndex) flush() error { return nil }
+
+// cidType returns the type recorded by the first document in key order which is not retracted.
+func (ci *cidIndex) cidType(c string) (string, bool) {
+	ci.mutex.RLock()
+	defer ci.mutex.RUnlock()
+	docKeys := make([]string, 0, len(ci.cids[c]))
+	for docKey := range ci.cids[c] {
+		if _, ok := ci.retracted[docKey]; !ok {
+			docKeys = append(docKeys, docKey)
+		}
+	}
+	if len(docKeys) == 0 {
+		return "", false
+	}
+	sort.Strings(docKeys)
+	return ci.cids[c][docKeys[0]], true
+}
+
+// CidType returns the data type of the cid, as recorded by any revision of a document which references it.
+// Cids referenced only by retracted documents are not found.
+func (ds *documentStore) CidType(c string) (string, error) {
+	if tp, ok := ds.cidIndex.cidType(c); ok {
+		return tp, nil
+	}
+	if !ds.watcher.behind() {
+		return "", errors.New("no document references the cid")
+	}
+
+	// the index may miss the document until the next poll
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return "", err
+	}
+	es, err := rs.Rest()
+	if err != nil {
+		return "", err
+	}
+	ci := newCidIndex()
+	for _, e := range es {
+		ci.put(e)
+	}
+	if tp, ok := ci.cidType(c); ok {
+		return tp, nil
+	}
+	return "", errors.New("no document references the cid")
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+func TestCidIndex(t *testing.T) {
+	info := NewDocumentInfo("title", "description", nil, nil, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	ci := newCidIndex()
+	ci.put(query.Entry{Key: "/pid/alice/doc", Value: newDocument(info, typedCid{"text", "old", 4}).Marshal()})
+	ci.put(query.Entry{Key: "/pid/revision/alice/doc/1", Value: newDocument(info, typedCid{"image", "new", 4}).Marshal()})
+	ci.put(query.Entry{Key: "/pid/bob/doc", Value: newDocument(info, typedCid{"pdf", "retracted", 4}).Marshal()})
+	ci.put(query.Entry{Key: "/pid/retraction/bob/doc", Value: (&Retraction{"reason", time.Now()}).Marshal()})
+	ci.put(query.Entry{Key: "/pid/carol/doc", Value: []byte("broken")})
+
+	tests := []struct {
+		cid   string
+		tp    string
+		found bool
+	}{
+		{"old", "text", true},
+		{"new", "image", true},
+		{"retracted", "", false},
+		{"unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cid, func(t *testing.T) {
+			tp, ok := ci.cidType(tt.cid)
+			if tp != tt.tp || ok != tt.found {
+				t.Errorf("got %q, %v, want %q, %v", tp, ok, tt.tp, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+	pv "github.com/pilinsin/p2p-verse"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+const commentCategory = "comment"
+
+// comment key: pid/comment/docpid/docusername/docname/id
+func commentPrefix(docKey string) string {
+	return commentCategory + "/" + strings.TrimPrefix(docKey, "/")
+}
+
+type Comment struct {
+	Doc    string
+	Parent string
+	Author string
+	Text   string
+	Time   time.Time
+}
+
+// parent is the key of the replied comment, or "" for a top level comment.
+func NewComment(docKey, parent, text string, t time.Time) *Comment {
+	docKey = strings.TrimPrefix(docKey, "/")
+	parent = strings.TrimPrefix(parent, "/")
+	return &Comment{docKey, parent, "", text, t}
+}
+func (c *Comment) Marshal() []byte {
+	mt, _ := c.Time.MarshalBinary()
+	mc := &pb.Comment{
+		Doc:    c.Doc,
+		Parent: c.Parent,
+		Name:   c.Author,
+		Text:   c.Text,
+		Time:   mt,
+	}
+	m, _ := proto.Marshal(mc)
+	return m
+}
+func (c *Comment) Unmarshal(m []byte) error {
+	mc := &pb.Comment{}
+	if err := proto.Unmarshal(m, mc); err != nil {
+		return err
+	}
+	t := time.Time{}
+	if err := t.UnmarshalBinary(mc.GetTime()); err != nil {
+		return err
+	}
+
+	c.Doc = mc.GetDoc()
+	c.Parent = mc.GetParent()
+	c.Author = mc.GetName()
+	c.Text = mc.GetText()
+	c.Time = t
+	return nil
+}
+
+type NamedComment struct {
+	*Comment
+	Name string
+}
+
+type CommentThread struct {
+	*NamedComment
+	Replies []*CommentThread
+}
+
+// NewCommentThreads builds reply trees sorted from older to newer.
+// A reply whose parent is unknown is treated as a top level comment.
+func NewCommentThreads(ncs []*NamedComment) []*CommentThread {
+	threads := make(map[string]*CommentThread, len(ncs))
+	for _, nc := range ncs {
+		threads[strings.TrimPrefix(nc.Name, "/")] = &CommentThread{nc, nil}
+	}
+
+	roots := make([]*CommentThread, 0)
+	for _, nc := range ncs {
+		th := threads[strings.TrimPrefix(nc.Name, "/")]
+		parent, ok := threads[nc.Parent]
+		if ok && parent != th {
+			parent.Replies = append(parent.Replies, th)
+		} else {
+			roots = append(roots, th)
+		}
+	}
+
+	sortThreads(roots)
+	return roots
+}
+func sortThreads(ths []*CommentThread) {
+	sort.SliceStable(ths, func(i, j int) bool {
+		return ths[i].Time.Before(ths[j].Time)
+	})
+	for _, th := range ths {
+		sortThreads(th.Replies)
+	}
+}
+
+func (ds *documentStore) PutComment(c *Comment) error {
+	return ds.PutCommentAs(ds.identity(), c)
+}
+func (ds *documentStore) PutCommentAs(ui *UserIdentity, c *Comment) error {
+	ui = parseUserIdentity(ui)
+	if len(splitKey(c.Doc)) != 3 {
+		return errors.New("invalid document key")
+	}
+	if c.Text == "" {
+		return errors.New("empty comment")
+	}
+
+	c.Author = ui.userName
+	id := pv.RandString(8)
+	return ds.put(ui, commentPrefix(c.Doc)+"/"+id, c.Marshal())
+}
+
+func (ds *documentStore) QueryComments(docKey string) (<-chan *NamedComment, error) {
+	docKey = strings.TrimPrefix(docKey, "/")
+	q := query.Query{
+		Filters: []query.Filter{
+			crdt.KeyMatchFilter{Key: "*/" + commentPrefix(docKey)},
+			commentFilter{},
+		},
+	}
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return nil, err
+	}
+	rs = query.NaiveQueryApply(q, rs)
+
+	ch := make(chan *NamedComment, 10)
+	go func() {
+		defer close(ch)
+		for res := range rs.Next() {
+			c := &Comment{}
+			if err := c.Unmarshal(res.Value); err != nil {
+				continue
+			}
+			ch <- &NamedComment{c, res.Key}
+		}
+	}()
+	return ch, nil
+}
+
+type commentFilter struct{}
+
+func (f commentFilter) Filter(e query.Entry) bool {
+	// e.Key: pid/comment/docpid/docusername/docname/id
+	keys := splitKey(e.Key)
+	if len(keys) != 6 || keys[1] != commentCategory {
+		return false
+	}
+
+	c := &Comment{}
+	if err := c.Unmarshal(e.Value); err != nil {
+		return false
+	}
+	return c.Doc == strings.Join(keys[2:5], "/")
+}
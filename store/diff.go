@@ -0,0 +1,64 @@
+package store
+
+import (
+	"sort"
+)
+
+// FieldDiff is a field which differs between two revisions.
+// title and description have one removed and one added value.
+type FieldDiff struct {
+	Field   string
+	Removed []string
+	Added   []string
+}
+
+// DiffRevisions returns the differences of the title, description, tags, types and cids
+// from the older revision to the newer one. Data is compared by cid.
+func DiffRevisions(older, newer *Document) []FieldDiff {
+	diffs := make([]FieldDiff, 0)
+	if older.Title != newer.Title {
+		diffs = append(diffs, FieldDiff{"title", []string{older.Title}, []string{newer.Title}})
+	}
+	if older.Description != newer.Description {
+		diffs = append(diffs, FieldDiff{"description", []string{older.Description}, []string{newer.Description}})
+	}
+	diffs = appendSetDiff(diffs, "tags", older.Tags, newer.Tags)
+	diffs = appendSetDiff(diffs, "types", older.DocTypes, newer.DocTypes)
+	diffs = appendSetDiff(diffs, "cids", cidValues(older.Cids), cidValues(newer.Cids))
+	return diffs
+}
+
+// cidValues are "type: cid".
+func cidValues(tcs []typedCid) []string {
+	values := make([]string, len(tcs))
+	for idx, tc := range tcs {
+		values[idx] = tc.Type + ": " + tc.Cid
+	}
+	return values
+}
+
+func appendSetDiff(diffs []FieldDiff, field string, older, newer []string) []FieldDiff {
+	removed := setDifference(older, newer)
+	added := setDifference(newer, older)
+	if len(removed) == 0 && len(added) == 0 {
+		return diffs
+	}
+	return append(diffs, FieldDiff{field, removed, added})
+}
+
+// setDifference returns the sorted values of a which are not in b.
+func setDifference(a, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+	diff := make(map[string]struct{})
+	for _, v := range a {
+		if _, ok := inB[v]; !ok {
+			diff[v] = struct{}{}
+		}
+	}
+	values := mapToSlice(diff)
+	sort.Strings(values)
+	return values
+}
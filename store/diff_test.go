@@ -0,0 +1,28 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	tags := map[string]struct{}{"a": {}, "b": {}}
+	older := newDocument(NewDocumentInfo("title", "description", map[string]struct{}{"text": {}}, tags, time.Now()),
+		typedCid{"text", "cid1", 0}, typedCid{"text", "cid2", 0})
+	tags = map[string]struct{}{"b": {}, "c": {}}
+	newer := newDocument(NewDocumentInfo("new title", "description", map[string]struct{}{"text": {}}, tags, time.Now()),
+		typedCid{"text", "cid2", 0}, typedCid{"text", "cid3", 0})
+
+	want := []FieldDiff{
+		{"title", []string{"title"}, []string{"new title"}},
+		{"tags", []string{"a"}, []string{"c"}},
+		{"cids", []string{"text: cid1"}, []string{"text: cid3"}},
+	}
+	if diffs := DiffRevisions(older, newer); !reflect.DeepEqual(diffs, want) {
+		t.Errorf("diffs %v, want %v", diffs, want)
+	}
+	if diffs := DiffRevisions(older, older); len(diffs) != 0 {
+		t.Errorf("diffs %v of the same revision", diffs)
+	}
+}
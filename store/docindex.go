@@ -0,0 +1,324 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+// documents are bucketed by month
+const timeBucketLayout = "2006-01"
+
+func timeBucket(t time.Time) string {
+	return t.UTC().Format(timeBucketLayout)
+}
+
+type indexedInfo struct {
+	revision int64
+	tags     []string
+	docTypes []string
+	time     time.Time
+}
+
+type postings map[string]map[string]struct{}
+
+func (p postings) add(value, key string) {
+	if _, ok := p[value]; !ok {
+		p[value] = make(map[string]struct{})
+	}
+	p[value][key] = struct{}{}
+}
+func (p postings) remove(value, key string) {
+	delete(p[value], key)
+	if len(p[value]) == 0 {
+		delete(p, value)
+	}
+}
+
+// docIndex is a local secondary index of documents by tag, doc type, author and time bucket.
+// It is persisted under the store's base dir (path == "" keeps it in memory only)
+// and is rebuilt from the signature store when it is found corrupted.
+type docIndex struct {
+	mutex    sync.RWMutex
+	path     string
+	docs     map[string]*indexedInfo
+	tags     postings
+	docTypes postings
+	authors  postings
+	buckets  postings
+	entries  map[string]struct{}
+	dirty    bool
+}
+
+func newDocIndex(path string) *docIndex {
+	di := &docIndex{path: path}
+	di.reset()
+	if err := di.load(); err != nil {
+		di.reset()
+		di.dirty = true
+	}
+	return di
+}
+func (di *docIndex) reset() {
+	di.docs = make(map[string]*indexedInfo)
+	di.tags = make(postings)
+	di.docTypes = make(postings)
+	di.authors = make(postings)
+	di.buckets = make(postings)
+	di.entries = make(map[string]struct{})
+}
+
+// the index file is the sha256 checksum followed by the marshaled index.
+func (di *docIndex) load() error {
+	if di.path == "" {
+		return nil
+	}
+	m, err := os.ReadFile(di.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(m) < sha256.Size {
+		return errors.New("corrupted index")
+	}
+	sum := sha256.Sum256(m[sha256.Size:])
+	if !bytes.Equal(sum[:], m[:sha256.Size]) {
+		return errors.New("corrupted index")
+	}
+	mdi := &pb.DocIndex{}
+	if err := proto.Unmarshal(m[sha256.Size:], mdi); err != nil {
+		return err
+	}
+
+	for _, mdoc := range mdi.GetDocs() {
+		if len(splitKey(mdoc.GetKey())) != 3 {
+			return errors.New("corrupted index")
+		}
+		t := time.Time{}
+		if err := t.UnmarshalBinary(mdoc.GetTime()); err != nil {
+			return err
+		}
+		di.setDoc(mdoc.GetKey(), &indexedInfo{mdoc.GetRevision(), mdoc.GetTags(), mdoc.GetTypes(), t})
+	}
+	for _, key := range mdi.GetEntries() {
+		di.entries[key] = struct{}{}
+	}
+	return nil
+}
+func (di *docIndex) flush() error {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+	if di.path == "" || !di.dirty {
+		return nil
+	}
+
+	mdi := &pb.DocIndex{
+		Docs:    make([]*pb.DocIndexDoc, 0, len(di.docs)),
+		Entries: mapToSlice(di.entries),
+	}
+	for key, info := range di.docs {
+		mt, _ := info.time.MarshalBinary()
+		mdi.Docs = append(mdi.Docs, &pb.DocIndexDoc{
+			Key:      key,
+			Revision: info.revision,
+			Tags:     info.tags,
+			Types:    info.docTypes,
+			Time:     mt,
+		})
+	}
+	m, err := proto.Marshal(mdi)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(m)
+	if err := writeFile(di.path, append(sum[:], m...)); err != nil {
+		return err
+	}
+	di.dirty = false
+	return nil
+}
+
+// clear drops everything so that the index is rebuilt from the next poll.
+func (di *docIndex) clear() {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+	di.reset()
+	di.dirty = true
+}
+
+func (di *docIndex) removeDoc(key string) {
+	info, ok := di.docs[key]
+	if !ok {
+		return
+	}
+	for _, tag := range info.tags {
+		di.tags.remove(tag, key)
+	}
+	for _, docType := range info.docTypes {
+		di.docTypes.remove(docType, key)
+	}
+	keys := splitKey(key)
+	di.authors.remove(keys[0], key)
+	di.authors.remove(keys[1], key)
+	di.authors.remove(keys[0]+"/"+keys[1], key)
+	di.buckets.remove(timeBucket(info.time), key)
+	delete(di.docs, key)
+}
+func (di *docIndex) setDoc(key string, info *indexedInfo) {
+	di.removeDoc(key)
+	for _, tag := range info.tags {
+		di.tags.add(tag, key)
+	}
+	for _, docType := range info.docTypes {
+		di.docTypes.add(docType, key)
+	}
+	keys := splitKey(key)
+	di.authors.add(keys[0], key)
+	di.authors.add(keys[1], key)
+	di.authors.add(keys[0]+"/"+keys[1], key)
+	di.buckets.add(timeBucket(info.time), key)
+	di.docs[key] = info
+}
+
+// put indexes a document entry or a revision entry.
+// An older revision than the indexed one is ignored.
+func (di *docIndex) put(e query.Entry) {
+	key := strings.TrimPrefix(e.Key, "/")
+	di.mutex.RLock()
+	_, indexed := di.entries[key]
+	di.mutex.RUnlock()
+	if indexed {
+		return
+	}
+
+	docKey, n, ok := revisionDocKey(key)
+	if !ok {
+		docKey, n = key, 0
+		if len(splitKey(key)) != 3 {
+			return
+		}
+	}
+	doc := newEmptyDocument()
+	if err := doc.Unmarshal(e.Value); err != nil || doc.Revision != n {
+		return
+	}
+
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+	if cur, ok := di.docs[docKey]; !ok || cur.revision < n {
+		di.setDoc(docKey, &indexedInfo{n, doc.Tags, doc.DocTypes, doc.Time})
+	}
+	di.entries[key] = struct{}{}
+	di.dirty = true
+}
+
+func intersect(a, b map[string]struct{}) map[string]struct{} {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	out := make(map[string]struct{}, len(a))
+	for key := range a {
+		if _, ok := b[key]; ok {
+			out[key] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (di *docIndex) filterKeys(f query.Filter) (map[string]struct{}, bool) {
+	var sets []map[string]struct{}
+	switch f := f.(type) {
+	case TagsFilter:
+		for _, tag := range f.Tags {
+			sets = append(sets, di.tags[tag])
+		}
+	case DocTypesFilter:
+		for _, docType := range f.DocTypes {
+			sets = append(sets, di.docTypes[docType])
+		}
+	case AuthorFilter:
+		sets = append(sets, di.authors[f.Author])
+	case TimeFilter:
+		keys := make(map[string]struct{})
+		for bucket, bKeys := range di.buckets {
+			begin, err := time.Parse(timeBucketLayout, bucket)
+			if err != nil {
+				continue
+			}
+			if !begin.Before(f.End) || !begin.AddDate(0, 1, 0).After(f.Begin) {
+				continue
+			}
+			for key := range bKeys {
+				keys[key] = struct{}{}
+			}
+		}
+		sets = append(sets, keys)
+	case AndFilter:
+		for _, sub := range f.Filters {
+			if keys, ok := di.filterKeys(sub); ok {
+				sets = append(sets, keys)
+			}
+		}
+	}
+	if len(sets) == 0 {
+		return nil, false
+	}
+
+	keys := sets[0]
+	for _, set := range sets[1:] {
+		keys = intersect(keys, set)
+	}
+	return keys, true
+}
+
+// candidates returns the indexed revision of each document which may match all of fs.
+// It returns false if none of fs can be served from the index.
+// The matching documents must still be checked by fs.
+func (di *docIndex) candidates(fs []query.Filter) (map[string]int64, bool) {
+	di.mutex.RLock()
+	defer di.mutex.RUnlock()
+
+	keys, ok := di.filterKeys(AndFilter{fs})
+	if !ok {
+		return nil, false
+	}
+	revs := make(map[string]int64, len(keys))
+	for key := range keys {
+		revs[key] = di.docs[key].revision
+	}
+	return revs, true
+}
+
+// indexedEntries fetches the latest revision of each candidate.
+// If a revision recorded in the index is missing, the index is rebuilt and false is returned.
+func (ds *documentStore) indexedEntries(revs map[string]int64, includeRetracted bool) ([]query.Entry, map[string]*Retraction, bool) {
+	es := make([]query.Entry, 0, len(revs))
+	retractions := make(map[string]*Retraction)
+	for key, n := range revs {
+		_, m, err := ds.latestRevisionFrom(key, n)
+		if err != nil {
+			ds.docIndex.clear()
+			ds.watcher.replay()
+			return nil, nil, false
+		}
+		if r := ds.retraction(key); r != nil {
+			retractions[key] = r
+			if !includeRetracted {
+				continue
+			}
+		}
+		es = append(es, query.Entry{Key: "/" + key, Value: m})
+	}
+	return es, retractions, true
+}
@@ -35,17 +35,21 @@ func NewDocumentInfo(title, description string, docTypesMap, tagsMap map[string]
 type typedCid struct {
 	Type string
 	Cid  string
+	// size of the data declared by the uploader, 0 if not recorded. It is not verified.
+	Size int64
 }
 
 func (tc *typedCid) encode() *pb.TypedCid {
 	return &pb.TypedCid{
 		Type: tc.Type,
 		Cid:  tc.Cid,
+		Size: tc.Size,
 	}
 }
 func (tc *typedCid) decode(pbtc *pb.TypedCid) {
 	tc.Type = pbtc.GetType()
 	tc.Cid = pbtc.GetCid()
+	tc.Size = pbtc.GetSize()
 }
 func encodeTypedCids(tcs []typedCid) []*pb.TypedCid {
 	pbtcs := make([]*pb.TypedCid, len(tcs))
@@ -65,6 +69,9 @@ func decodeTypedCids(pbtcs []*pb.TypedCid) []typedCid {
 type Document struct {
 	*DocumentInfo
 	Cids []typedCid
+	// cid of the previous revision, "" for the first revision
+	Prev     string
+	Revision int64
 }
 
 func newEmptyDocument() *Document {
@@ -75,17 +82,19 @@ func newEmptyDocument() *Document {
 	}
 }
 func newDocument(di *DocumentInfo, cids ...typedCid) *Document {
-	return &Document{di, cids}
+	return &Document{di, cids, "", 0}
 }
 func (d *Document) Marshal() []byte {
 	mt, _ := d.Time.MarshalBinary()
 	mui := &pb.Document{
-		Cids:   encodeTypedCids(d.Cids),
-		Title:  d.Title,
-		Time:   mt,
-		Types:  d.DocTypes,
-		Tags:   d.Tags,
-		Dscrpt: d.Description,
+		Cids:     encodeTypedCids(d.Cids),
+		Title:    d.Title,
+		Time:     mt,
+		Types:    d.DocTypes,
+		Tags:     d.Tags,
+		Dscrpt:   d.Description,
+		Prev:     d.Prev,
+		Revision: d.Revision,
 	}
 	m, _ := proto.Marshal(mui)
 	return m
@@ -106,10 +115,16 @@ func (d *Document) Unmarshal(m []byte) error {
 	d.DocTypes = md.GetTypes()
 	d.Tags = md.GetTags()
 	d.Description = md.GetDscrpt()
+	d.Prev = md.GetPrev()
+	d.Revision = md.GetRevision()
 	return nil
 }
 
 type NamedDocument struct {
 	*Document
 	Name string
+	// nil if the document is not retracted
+	Retraction *Retraction
+	// the signer of the revision
+	Signer *Signer
 }
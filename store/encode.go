@@ -0,0 +1,124 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+var extTypes = map[string]string{
+	".txt":  "text",
+	".md":   "text",
+	".png":  "image",
+	".jpg":  "image",
+	".jpeg": "image",
+	".gif":  "image",
+	".webp": "image",
+	".bmp":  "image",
+	".tif":  "image",
+	".tiff": "image",
+	".pdf":  "pdf",
+	".mp4":  "video",
+	".mkv":  "video",
+	".webm": "video",
+	".avi":  "video",
+	".mov":  "video",
+	".mp3":  "audio",
+	".wav":  "audio",
+	".ogg":  "audio",
+	".flac": "audio",
+	".m4a":  "audio",
+}
+
+// DetectType detects the data type by the extension, or by the contents.
+func DetectType(path string) (string, error) {
+	if tp, ok := extTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return tp, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := f.Read(head)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	ct := http.DetectContentType(head[:n])
+	switch {
+	case strings.HasPrefix(ct, "text/"):
+		return "text", nil
+	case strings.HasPrefix(ct, "image/"):
+		return "image", nil
+	case ct == "application/pdf":
+		return "pdf", nil
+	case strings.HasPrefix(ct, "video/"):
+		return "video", nil
+	case strings.HasPrefix(ct, "audio/"):
+		return "audio", nil
+	default:
+		return "", errors.New("unknown type of " + path)
+	}
+}
+
+// fileReader passes a local file to the encoders, which read fyne uris.
+type fileReader struct {
+	*os.File
+	uri fyne.URI
+}
+
+func (fr fileReader) URI() fyne.URI { return fr.uri }
+
+func openFile(path string) (fileReader, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fileReader{}, err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return fileReader{}, err
+	}
+	return fileReader{f, storage.NewFileURI(abs)}, nil
+}
+
+// EncodeFile encodes a local file as the data of its type.
+func EncodeFile(path string) (*TypedData, error) {
+	tp, err := DetectType(path)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+
+	var r io.Reader
+	switch tp {
+	case "text":
+		var m []byte
+		m, err = io.ReadAll(fr)
+		r = bytes.NewBuffer(m)
+	case "image":
+		r, err = EncodeImage(fr)
+	case "pdf":
+		r, err = EncodePdf(fr)
+	case "video":
+		r, err = EncodeVideo(fr)
+	case "audio":
+		r, err = EncodeAudio(fr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewTypedData(tp, r), nil
+}
@@ -0,0 +1,396 @@
+package store
+
+import (
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+)
+
+const textFetchTimeout = time.Second * 30
+
+// entries whose text cannot be fetched are retried with exponential backoff
+const (
+	textRetryMinBackoff = time.Minute
+	textRetryMaxBackoff = time.Hour
+)
+
+// term frequencies are multiplied by the field weight
+const (
+	titleWeight       = 3
+	tagWeight         = 2
+	descriptionWeight = 1
+	textWeight        = 1
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// tokenize lowercases text and splits it into words.
+// CJK runs have no word boundaries, so they are split into bigrams.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		run := make([]rune, 0)
+		flush := func() {
+			if len(run) == 1 {
+				tokens = append(tokens, string(run))
+			}
+			for idx := 0; idx+1 < len(run); idx++ {
+				tokens = append(tokens, string(run[idx:idx+2]))
+			}
+			run = run[:0]
+		}
+
+		start := 0
+		rs := []rune(word)
+		for idx, r := range rs {
+			if isCJK(r) {
+				if start < idx {
+					tokens = append(tokens, string(rs[start:idx]))
+				}
+				start = idx + 1
+				run = append(run, r)
+			} else {
+				flush()
+			}
+		}
+		flush()
+		if start < len(rs) {
+			tokens = append(tokens, string(rs[start:]))
+		}
+	}
+	return tokens
+}
+
+func addTerms(terms map[string]int32, text string, weight int32) {
+	for _, token := range tokenize(text) {
+		terms[token] += weight
+	}
+}
+
+type indexedDoc struct {
+	revision int64
+	terms    map[string]int32
+	length   int
+}
+
+func newIndexedDoc(revision int64, terms map[string]int32) *indexedDoc {
+	length := 0
+	for _, tf := range terms {
+		length += int(tf)
+	}
+	return &indexedDoc{revision, terms, length}
+}
+
+type failedText struct {
+	e        query.Entry
+	attempts int
+	next     time.Time
+}
+
+// textIndex is a local inverted index over titles, descriptions, tags and text media.
+// It is persisted under the store's base dir (path == "" keeps it in memory only).
+type textIndex struct {
+	mutex    sync.RWMutex
+	path     string
+	is       ipfs.Ipfs
+	docs     map[string]*indexedDoc
+	postings map[string]map[string]int32
+	entries  map[string]struct{}
+	totalLen int
+	dirty    bool
+	// entries whose text could not be fetched. They are indexed without the text
+	// and not recorded in entries, so they are indexed again after a restart too.
+	failed map[string]*failedText
+}
+
+func newTextIndex(path string, is ipfs.Ipfs) *textIndex {
+	ti := &textIndex{
+		path:     path,
+		is:       is,
+		docs:     make(map[string]*indexedDoc),
+		postings: make(map[string]map[string]int32),
+		entries:  make(map[string]struct{}),
+		failed:   make(map[string]*failedText),
+	}
+	if err := ti.load(); err != nil {
+		ti.reset()
+	}
+	return ti
+}
+func (ti *textIndex) reset() {
+	ti.docs = make(map[string]*indexedDoc)
+	ti.postings = make(map[string]map[string]int32)
+	ti.entries = make(map[string]struct{})
+	ti.totalLen = 0
+}
+
+func (ti *textIndex) load() error {
+	if ti.path == "" {
+		return nil
+	}
+	m, err := os.ReadFile(ti.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	mti := &pb.TextIndex{}
+	if err := proto.Unmarshal(m, mti); err != nil {
+		return err
+	}
+
+	for _, mdoc := range mti.GetDocs() {
+		ti.setDoc(mdoc.GetKey(), newIndexedDoc(mdoc.GetRevision(), mdoc.GetTerms()))
+	}
+	for _, key := range mti.GetEntries() {
+		ti.entries[key] = struct{}{}
+	}
+	return nil
+}
+func (ti *textIndex) flush() error {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	if ti.path == "" || !ti.dirty {
+		return nil
+	}
+
+	mti := &pb.TextIndex{
+		Docs:    make([]*pb.TextIndexDoc, 0, len(ti.docs)),
+		Entries: mapToSlice(ti.entries),
+	}
+	for key, doc := range ti.docs {
+		mti.Docs = append(mti.Docs, &pb.TextIndexDoc{
+			Key:      key,
+			Revision: doc.revision,
+			Terms:    doc.terms,
+		})
+	}
+	m, err := proto.Marshal(mti)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(ti.path, m); err != nil {
+		return err
+	}
+	ti.dirty = false
+	return nil
+}
+
+func (ti *textIndex) removeDoc(key string) {
+	doc, ok := ti.docs[key]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(ti.postings[term], key)
+		if len(ti.postings[term]) == 0 {
+			delete(ti.postings, term)
+		}
+	}
+	ti.totalLen -= doc.length
+	delete(ti.docs, key)
+}
+func (ti *textIndex) setDoc(key string, doc *indexedDoc) {
+	ti.removeDoc(key)
+	for term, tf := range doc.terms {
+		if _, ok := ti.postings[term]; !ok {
+			ti.postings[term] = make(map[string]int32)
+		}
+		ti.postings[term][key] = tf
+	}
+	ti.totalLen += doc.length
+	ti.docs[key] = doc
+}
+
+// documentTerms returns the terms of the document,
+// and false if some text could not be fetched and is missing from them.
+func (ti *textIndex) documentTerms(d *Document) (map[string]int32, bool) {
+	terms := make(map[string]int32)
+	addTerms(terms, d.Title, titleWeight)
+	addTerms(terms, d.Description, descriptionWeight)
+	for _, tag := range d.Tags {
+		addTerms(terms, tag, tagWeight)
+	}
+	complete := true
+	for _, tc := range d.Cids {
+		if tc.Type != "text" {
+			continue
+		}
+		m, err := ti.is.Get(tc.Cid, textFetchTimeout)
+		if err != nil {
+			complete = false
+			continue
+		}
+		addTerms(terms, string(m), textWeight)
+	}
+	return terms, complete
+}
+
+// put indexes a document entry or a revision entry.
+// An older revision than the indexed one is ignored.
+// If some text cannot be fetched, the entry is indexed without it and retried later.
+func (ti *textIndex) put(e query.Entry) {
+	key := strings.TrimPrefix(e.Key, "/")
+	ti.mutex.RLock()
+	_, indexed := ti.entries[key]
+	ti.mutex.RUnlock()
+	if indexed {
+		return
+	}
+
+	docKey, n, ok := revisionDocKey(key)
+	if !ok {
+		docKey, n = key, 0
+		if !(documentFilter{}).Filter(e) {
+			return
+		}
+	}
+	doc := newEmptyDocument()
+	if err := doc.Unmarshal(e.Value); err != nil || doc.Revision != n {
+		return
+	}
+
+	ti.mutex.RLock()
+	cur, ok := ti.docs[docKey]
+	_, retrying := ti.failed[key]
+	ti.mutex.RUnlock()
+	var terms map[string]int32
+	complete := true
+	if !ok || cur.revision < n || (retrying && cur.revision == n) {
+		terms, complete = ti.documentTerms(doc)
+	}
+
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	if terms != nil {
+		ti.setDoc(docKey, newIndexedDoc(n, terms))
+		ti.dirty = true
+	}
+	if !complete {
+		ti.fail(key, e)
+		return
+	}
+	delete(ti.failed, key)
+	ti.entries[key] = struct{}{}
+	ti.dirty = true
+}
+
+// fail schedules the next attempt of the entry. It must be called with the lock held.
+func (ti *textIndex) fail(key string, e query.Entry) {
+	ft, ok := ti.failed[key]
+	if !ok {
+		ft = &failedText{e: e}
+		ti.failed[key] = ft
+	}
+	backoff := textRetryMinBackoff << ft.attempts
+	if backoff > textRetryMaxBackoff || backoff <= 0 {
+		backoff = textRetryMaxBackoff
+	}
+	ft.attempts++
+	ft.next = time.Now().Add(backoff)
+}
+
+// retry indexes the failed entries which are due again. It returns false if none is due.
+func (ti *textIndex) retry() bool {
+	ti.mutex.RLock()
+	now := time.Now()
+	es := make([]query.Entry, 0)
+	for _, ft := range ti.failed {
+		if !now.Before(ft.next) {
+			es = append(es, ft.e)
+		}
+	}
+	ti.mutex.RUnlock()
+
+	for _, e := range es {
+		ti.put(e)
+	}
+	return len(es) > 0
+}
+
+// scores returns the BM25 score of each document matching at least one term of text.
+func (ti *textIndex) scores(text string) map[string]float64 {
+	ti.mutex.RLock()
+	defer ti.mutex.RUnlock()
+
+	scores := make(map[string]float64)
+	nDocs := float64(len(ti.docs))
+	if nDocs == 0 {
+		return scores
+	}
+	avgLen := float64(ti.totalLen) / nDocs
+
+	queried := make(map[string]struct{})
+	for _, term := range tokenize(text) {
+		if _, ok := queried[term]; ok {
+			continue
+		}
+		queried[term] = struct{}{}
+
+		posting := ti.postings[term]
+		df := float64(len(posting))
+		idf := math.Log(1 + (nDocs-df+0.5)/(df+0.5))
+		for key, tf := range posting {
+			docLen := float64(ti.docs[key].length)
+			norm := float64(tf) + bm25K1*(1-bm25B+bm25B*docLen/avgLen)
+			scores[key] += idf * float64(tf) * (bm25K1 + 1) / norm
+		}
+	}
+	return scores
+}
+
+// RelevanceOrder sorts documents by their BM25 score (higher first).
+// It is also a query.Filter which keeps only the documents matching the text.
+type RelevanceOrder struct {
+	Scores map[string]float64
+}
+
+func (o RelevanceOrder) score(e query.Entry) (float64, bool) {
+	s, ok := o.Scores[strings.TrimPrefix(e.Key, "/")]
+	return s, ok
+}
+func (o RelevanceOrder) Filter(e query.Entry) bool {
+	_, ok := o.score(e)
+	return ok
+}
+
+func (o RelevanceOrder) Compare(a, b query.Entry) int {
+	sa, _ := o.score(a)
+	sb, _ := o.score(b)
+	if sa == sb {
+		return 0
+	}
+	if sa > sb {
+		return -1
+	} else {
+		return 1
+	}
+}
+
+// Relevance searches the full-text index.
+// The index is updated in the background, so very new documents may be missing.
+func (ds *documentStore) Relevance(text string) RelevanceOrder {
+	return RelevanceOrder{ds.textIndex.scores(text)}
+}
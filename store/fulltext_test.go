@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+)
+
+// flakyIpfs fails to get data until it is online.
+type flakyIpfs struct {
+	ipfs.Ipfs
+	online bool
+	data   map[string][]byte
+}
+
+func (fi *flakyIpfs) Get(c string, timeouts ...time.Duration) ([]byte, error) {
+	if !fi.online {
+		return nil, errors.New("timeout")
+	}
+	return fi.data[c], nil
+}
+
+func TestTextIndexRetry(t *testing.T) {
+	fi := &flakyIpfs{data: map[string][]byte{"text": []byte("needle")}}
+	ti := newTextIndex("", fi)
+	info := NewDocumentInfo("title", "description", nil, nil, time.Now())
+	doc := newDocument(info, typedCid{"text", "text", 0})
+	e := query.Entry{Key: "/pid/alice/doc", Value: doc.Marshal()}
+
+	ti.put(e)
+	if _, ok := ti.scores("title")["pid/alice/doc"]; !ok {
+		t.Error("the document is not indexed without its text")
+	}
+	if _, ok := ti.entries["pid/alice/doc"]; ok {
+		t.Fatal("the entry is recorded as indexed without its text")
+	}
+	if ti.retry() {
+		t.Error("retried before the backoff")
+	}
+
+	fi.online = true
+	ti.failed["pid/alice/doc"].next = time.Now()
+	if !ti.retry() {
+		t.Fatal("not retried after the backoff")
+	}
+	if _, ok := ti.scores("needle")["pid/alice/doc"]; !ok {
+		t.Error("the text is not indexed by the retry")
+	}
+	if _, ok := ti.entries["pid/alice/doc"]; !ok {
+		t.Error("the entry is not recorded as indexed")
+	}
+	if len(ti.failed) != 0 {
+		t.Errorf("%d entries still failed", len(ti.failed))
+	}
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// data added or used within gcGracePeriod is kept even if no document references it yet,
+// e.g. the data of an upload which is not put yet.
+const gcGracePeriod = time.Hour
+
+type GCReport struct {
+	// the number and the total size of the unreferenced blocks
+	Blocks int
+	Freed  int64
+	// blocks are only counted, not removed
+	DryRun bool
+	// the data which is only partly local. Its missing blocks may hide referenced blocks,
+	// so nothing is removed while there is any.
+	Incomplete []string
+}
+
+// markBlocks returns the local blocks reachable from the data of all document revisions,
+// the pinned documents and the recently used data,
+// and the data whose blocks are not all found.
+func (ds *documentStore) markBlocks(lb *localBlocks) (map[cid.Cid]struct{}, []string, error) {
+	roots, err := ds.documentCids()
+	if err != nil {
+		return nil, nil, err
+	}
+	for c := range ds.pinnedCids() {
+		roots = append(roots, c)
+	}
+	ds.pins.mutex.Lock()
+	for c, t := range ds.pins.accessed {
+		if time.Since(t) < gcGracePeriod {
+			roots = append(roots, c)
+		}
+	}
+	ds.pins.mutex.Unlock()
+
+	marked := make(map[cid.Cid]struct{})
+	incomplete := make([]string, 0)
+	for _, c := range roots {
+		if found, complete := lb.dataBlocks(ds.ctx, c, marked); found && !complete {
+			incomplete = append(incomplete, c)
+		}
+	}
+	sort.Strings(incomplete)
+	return marked, incomplete, nil
+}
+
+// GC removes the local blocks which no document references,
+// e.g. the blocks left by an overwritten document or a failed Put.
+// With dryRun, it only reports what would be removed.
+// Nothing is removed while some data is only partly local (see GCReport.Incomplete).
+// Adding data waits until GC returns.
+func (ds *documentStore) GC(dryRun bool) (*GCReport, error) {
+	lb, err := newLocalBlocks(ds.is)
+	if err != nil {
+		return nil, err
+	}
+
+	ds.pins.adding.Lock()
+	defer ds.pins.adding.Unlock()
+	marked, incomplete, err := ds.markBlocks(lb)
+	if err != nil {
+		return nil, err
+	}
+	report := &GCReport{DryRun: dryRun || len(incomplete) > 0, Incomplete: incomplete}
+	if err := lb.sweep(ds.ctx, marked, report); err != nil {
+		return nil, err
+	}
+	if report.DryRun {
+		return report, nil
+	}
+
+	// forget the access times of the removed data
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	for cStr := range ds.pins.accessed {
+		if c, err := cid.Decode(cStr); err != nil {
+			delete(ds.pins.accessed, cStr)
+		} else if _, ok := marked[blockKey(c)]; !ok {
+			delete(ds.pins.accessed, cStr)
+		}
+	}
+	return report, ds.pins.flush()
+}
+
+// sweep removes the unmarked local blocks and counts them in report.
+// With report.DryRun, they are only counted.
+func (lb *localBlocks) sweep(ctx context.Context, marked map[cid.Cid]struct{}, report *GCReport) error {
+	sizes, err := lb.all(ctx)
+	if err != nil {
+		return err
+	}
+	for c, size := range sizes {
+		if _, ok := marked[c]; ok {
+			continue
+		}
+		if !report.DryRun {
+			if err := lb.remove(ctx, c); err != nil {
+				continue
+			}
+		}
+		report.Blocks++
+		report.Freed += size
+	}
+	return nil
+}
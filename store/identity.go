@@ -1,12 +1,16 @@
 package store
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"strings"
 
 	proto "google.golang.org/protobuf/proto"
 
 	pb "github.com/pilinsin/lontan/store/pb"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
 )
 
 type UserIdentity struct {
@@ -22,6 +26,29 @@ func (ui UserIdentity) UserName() string { return ui.userName }
 func (ui UserIdentity) Verify() IVerfKey { return ui.verfKey }
 func (ui UserIdentity) Sign() ISignKey   { return ui.signKey }
 
+// Fingerprint is a short hash of the verify key, for telling identities apart.
+func (ui UserIdentity) Fingerprint() string { return Fingerprint(ui.verfKey) }
+
+// Pid is the first part of the keys of the entries the user puts.
+func (ui UserIdentity) Pid() string { return crdt.PubKeyToStr(ui.verfKey) }
+
+// Fingerprint returns the first 8 bytes of the sha256 of the raw verify key, in hex groups.
+func Fingerprint(verf IVerfKey) string {
+	mv, err := verf.Raw()
+	if err != nil {
+		return ""
+	}
+	return fingerprint(mv)
+}
+func fingerprint(mv []byte) string {
+	sum := sha256.Sum256(mv)
+	groups := make([]string, 4)
+	for idx := range groups {
+		groups[idx] = hex.EncodeToString(sum[2*idx : 2*idx+2])
+	}
+	return strings.Join(groups, ":")
+}
+
 func (ui *UserIdentity) Marshal() []byte {
 	mv, _ := ui.verfKey.Raw()
 	ms, _ := ui.signKey.Raw()
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"sync"
+)
+
+// keyLocks serializes the puts which allocate the next number under a key,
+// such as the revisions of a document and the ratings of a user.
+type keyLocks struct {
+	mutex sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	mutex sync.Mutex
+	users int
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{locks: make(map[string]*keyLock)}
+}
+
+// lock returns the unlock func. Unused locks are dropped, so the map does not grow.
+func (kl *keyLocks) lock(key string) func() {
+	kl.mutex.Lock()
+	l, ok := kl.locks[key]
+	if !ok {
+		l = &keyLock{}
+		kl.locks[key] = l
+	}
+	l.users++
+	kl.mutex.Unlock()
+
+	l.mutex.Lock()
+	return func() {
+		l.mutex.Unlock()
+		kl.mutex.Lock()
+		defer kl.mutex.Unlock()
+		l.users--
+		if l.users == 0 {
+			delete(kl.locks, key)
+		}
+	}
+}
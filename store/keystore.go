@@ -0,0 +1,381 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+// argon2id parameters of new keystores. Opened keystores use the recorded ones.
+const (
+	keystoreTime    = 3
+	keystoreMemory  = 64 * 1024
+	keystoreThreads = 4
+)
+
+// bounds of the recorded parameters, so that a crafted file cannot make
+// argon2 panic (time or threads of 0) or allocate too much memory (in KiB).
+const (
+	maxKeystoreTime    = 16
+	maxKeystoreMemory  = 1024 * 1024
+	maxKeystoreThreads = 64
+	minKeystoreSalt    = 8
+)
+
+func KeystorePath() string {
+	return BaseDir("keystore")
+}
+
+type KeystoreEntry struct {
+	Name     string
+	Identity *UserIdentity
+}
+
+// Keystore keeps named user identities in a file encrypted with a passphrase.
+// The key is derived with argon2id and the entries are sealed with AES-256-GCM.
+// It also keeps the default identity of each store, by StoreKey.
+type Keystore struct {
+	mutex    sync.Mutex
+	path     string
+	mks      *pb.Keystore
+	key      []byte
+	entries  map[string]*UserIdentity
+	defaults map[string]string
+}
+
+func deriveKey(passphrase string, mks *pb.Keystore) []byte {
+	return argon2.IDKey([]byte(passphrase), mks.GetSalt(), mks.GetTime(), mks.GetMemory(), uint8(mks.GetThreads()), 32)
+}
+
+func checkKeystoreParams(mks *pb.Keystore) error {
+	if mks.GetTime() == 0 || mks.GetTime() > maxKeystoreTime {
+		return errors.New("invalid keystore time")
+	}
+	if mks.GetThreads() == 0 || mks.GetThreads() > maxKeystoreThreads {
+		return errors.New("invalid keystore threads")
+	}
+	if mks.GetMemory() < 8*mks.GetThreads() || mks.GetMemory() > maxKeystoreMemory {
+		return errors.New("invalid keystore memory")
+	}
+	if len(mks.GetSalt()) < minKeystoreSalt {
+		return errors.New("invalid keystore salt")
+	}
+	return nil
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newKeystoreParams() (*pb.Keystore, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &pb.Keystore{
+		Salt:    salt,
+		Time:    keystoreTime,
+		Memory:  keystoreMemory,
+		Threads: keystoreThreads,
+	}, nil
+}
+
+// sealEntries encrypts mes into mks with a new nonce.
+func sealEntries(mks *pb.Keystore, key []byte, mes *pb.KeystoreEntries) ([]byte, error) {
+	md, err := proto.Marshal(mes)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	mks.Nonce = nonce
+	mks.Data = aead.Seal(nil, nonce, md, mks.GetSalt())
+	return proto.Marshal(mks)
+}
+
+func openEntries(m []byte, passphrase string) (*pb.Keystore, []byte, *pb.KeystoreEntries, error) {
+	mks := &pb.Keystore{}
+	if err := proto.Unmarshal(m, mks); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := checkKeystoreParams(mks); err != nil {
+		return nil, nil, nil, err
+	}
+	key := deriveKey(passphrase, mks)
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Open panics with a nonce of another size
+	if len(mks.GetNonce()) != aead.NonceSize() {
+		return nil, nil, nil, errors.New("broken keystore")
+	}
+	md, err := aead.Open(nil, mks.GetNonce(), mks.GetData(), mks.GetSalt())
+	if err != nil {
+		return nil, nil, nil, errors.New("wrong passphrase or broken keystore")
+	}
+	mes := &pb.KeystoreEntries{}
+	if err := proto.Unmarshal(md, mes); err != nil {
+		return nil, nil, nil, err
+	}
+	return mks, key, mes, nil
+}
+
+func unmarshalEntries(mes *pb.KeystoreEntries) (map[string]*UserIdentity, error) {
+	entries := make(map[string]*UserIdentity)
+	for _, me := range mes.GetEntries() {
+		ui := &UserIdentity{}
+		if err := ui.Unmarshal(me.GetIdentity()); err != nil {
+			return nil, err
+		}
+		entries[me.GetName()] = ui
+	}
+	return entries, nil
+}
+
+// OpenKeystore unlocks the keystore at path, or makes an empty one if there is no file.
+func OpenKeystore(path, passphrase string) (*Keystore, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	ks := &Keystore{
+		path:     path,
+		entries:  make(map[string]*UserIdentity),
+		defaults: make(map[string]string),
+	}
+
+	m, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		mks, err := newKeystoreParams()
+		if err != nil {
+			return nil, err
+		}
+		ks.mks = mks
+		ks.key = deriveKey(passphrase, mks)
+		return ks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mks, key, mes, err := openEntries(m, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := unmarshalEntries(mes)
+	if err != nil {
+		return nil, err
+	}
+	ks.mks, ks.key, ks.entries = mks, key, entries
+	for stKey, name := range mes.GetDefaults() {
+		ks.defaults[stKey] = name
+	}
+	return ks, nil
+}
+
+// save must be called with the lock held.
+func (ks *Keystore) save() error {
+	mes := &pb.KeystoreEntries{Defaults: ks.defaults}
+	for name, ui := range ks.entries {
+		mes.Entries = append(mes.Entries, &pb.KeystoreEntry{
+			Name:     name,
+			Identity: ui.Marshal(),
+		})
+	}
+	m, err := sealEntries(ks.mks, ks.key, mes)
+	if err != nil {
+		return err
+	}
+	return writeFile(ks.path, m)
+}
+
+// Entries returns the identities sorted by name.
+func (ks *Keystore) Entries() []*KeystoreEntry {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	kes := make([]*KeystoreEntry, 0, len(ks.entries))
+	for name, ui := range ks.entries {
+		kes = append(kes, &KeystoreEntry{name, ui})
+	}
+	sort.Slice(kes, func(i, j int) bool { return kes[i].Name < kes[j].Name })
+	return kes
+}
+
+func (ks *Keystore) Get(name string) (*UserIdentity, error) {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ui, ok := ks.entries[name]
+	if !ok {
+		return nil, errors.New("no such identity")
+	}
+	return ui, nil
+}
+
+func (ks *Keystore) Add(name string, ui *UserIdentity) error {
+	if name == "" {
+		return errors.New("empty name")
+	}
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	if _, ok := ks.entries[name]; ok {
+		return errors.New("identity already exists")
+	}
+	ks.entries[name] = ui
+	if err := ks.save(); err != nil {
+		delete(ks.entries, name)
+		return err
+	}
+	return nil
+}
+
+// Remove deletes the identity and unsets it as a default.
+func (ks *Keystore) Remove(name string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ui, ok := ks.entries[name]
+	if !ok {
+		return errors.New("no such identity")
+	}
+	defaults := make(map[string]string)
+	for stKey, dName := range ks.defaults {
+		defaults[stKey] = dName
+	}
+
+	delete(ks.entries, name)
+	for stKey, dName := range defaults {
+		if dName == name {
+			delete(ks.defaults, stKey)
+		}
+	}
+	if err := ks.save(); err != nil {
+		ks.entries[name] = ui
+		ks.defaults = defaults
+		return err
+	}
+	return nil
+}
+
+// Default returns the name of the default identity of the store, or "" if there is none.
+func (ks *Keystore) Default(stKey string) string {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	return ks.defaults[stKey]
+}
+
+// SetDefault sets the default identity of the store. An empty name unsets it.
+func (ks *Keystore) SetDefault(stKey, name string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	if _, ok := ks.entries[name]; name != "" && !ok {
+		return errors.New("no such identity")
+	}
+	old, hadOld := ks.defaults[stKey]
+	if name == "" {
+		delete(ks.defaults, stKey)
+	} else {
+		ks.defaults[stKey] = name
+	}
+	if err := ks.save(); err != nil {
+		if hadOld {
+			ks.defaults[stKey] = old
+		} else {
+			delete(ks.defaults, stKey)
+		}
+		return err
+	}
+	return nil
+}
+
+// ExportIdentity writes the identity encrypted with its own passphrase,
+// in the format of a keystore file with one entry.
+func ExportIdentity(w io.Writer, passphrase, name string, ui *UserIdentity) error {
+	if passphrase == "" {
+		return errors.New("empty passphrase")
+	}
+	mks, err := newKeystoreParams()
+	if err != nil {
+		return err
+	}
+	mes := &pb.KeystoreEntries{
+		Entries: []*pb.KeystoreEntry{{Name: name, Identity: ui.Marshal()}},
+	}
+	m, err := sealEntries(mks, deriveKey(passphrase, mks), mes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(m)
+	return err
+}
+
+// ImportIdentities reads identities written by ExportIdentity, or a whole keystore file.
+func ImportIdentities(r io.Reader, passphrase string) ([]*KeystoreEntry, error) {
+	m, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, _, mes, err := openEntries(m, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := unmarshalEntries(mes)
+	if err != nil {
+		return nil, err
+	}
+	kes := make([]*KeystoreEntry, 0, len(entries))
+	for name, ui := range entries {
+		kes = append(kes, &KeystoreEntry{name, ui})
+	}
+	sort.Slice(kes, func(i, j int) bool { return kes[i].Name < kes[j].Name })
+	return kes, nil
+}
+
+// KeystoreIdentity reads an identity from a keystore file or a file written by ExportIdentity.
+// Without name, it is the default identity of the store at addr, or the only identity of the file.
+func KeystoreIdentity(m []byte, passphrase, name, addr string) (*UserIdentity, error) {
+	_, _, mes, err := openEntries(m, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := unmarshalEntries(mes)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		if stKey, err := StoreKey(addr); err == nil {
+			name = mes.GetDefaults()[stKey]
+		}
+	}
+	if name == "" && len(entries) == 1 {
+		for n := range entries {
+			name = n
+		}
+	}
+	if name == "" {
+		return nil, errors.New("no identity is selected")
+	}
+	ui, ok := entries[name]
+	if !ok {
+		return nil, errors.New("no such identity")
+	}
+	return ui, nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+// cheap parameters, so that tests derive keys fast
+func testKeystoreParams() *pb.Keystore {
+	return &pb.Keystore{
+		Salt:    bytes.Repeat([]byte{1}, 16),
+		Time:    1,
+		Memory:  64,
+		Threads: 1,
+	}
+}
+
+func sealTestEntries(t *testing.T, mks *pb.Keystore, passphrase string) []byte {
+	mes := &pb.KeystoreEntries{
+		Entries:  []*pb.KeystoreEntry{{Name: "alice", Identity: []byte("identity")}},
+		Defaults: map[string]string{"store": "alice"},
+	}
+	m, err := sealEntries(mks, deriveKey(passphrase, mks), mes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestOpenEntries(t *testing.T) {
+	m := sealTestEntries(t, testKeystoreParams(), "pass")
+	_, _, mes, err := openEntries(m, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mes.GetEntries()) != 1 || mes.GetEntries()[0].GetName() != "alice" {
+		t.Errorf("entries %v, want alice", mes.GetEntries())
+	}
+	if !bytes.Equal(mes.GetEntries()[0].GetIdentity(), []byte("identity")) {
+		t.Errorf("identity %q, want %q", mes.GetEntries()[0].GetIdentity(), "identity")
+	}
+	if mes.GetDefaults()["store"] != "alice" {
+		t.Errorf("defaults %v, want store: alice", mes.GetDefaults())
+	}
+
+	if _, _, _, err := openEntries(m, "wrong"); err == nil {
+		t.Error("opened with a wrong passphrase")
+	}
+}
+
+func TestOpenEntriesInvalidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*pb.Keystore)
+	}{
+		{"zero time", func(mks *pb.Keystore) { mks.Time = 0 }},
+		{"huge time", func(mks *pb.Keystore) { mks.Time = 1 << 30 }},
+		{"zero threads", func(mks *pb.Keystore) { mks.Threads = 0 }},
+		{"too many threads", func(mks *pb.Keystore) { mks.Threads = 256 }},
+		{"huge memory", func(mks *pb.Keystore) { mks.Memory = 1 << 31 }},
+		{"memory below threads", func(mks *pb.Keystore) { mks.Threads, mks.Memory = 4, 16 }},
+		{"short salt", func(mks *pb.Keystore) { mks.Salt = []byte{1} }},
+		{"short nonce", func(mks *pb.Keystore) { mks.Nonce = mks.Nonce[:4] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mks := &pb.Keystore{}
+			if err := proto.Unmarshal(sealTestEntries(t, testKeystoreParams(), "pass"), mks); err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(mks)
+			m, err := proto.Marshal(mks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, _, err := openEntries(m, "pass"); err == nil {
+				t.Error("opened a keystore with invalid parameters")
+			}
+		})
+	}
+}
+
+func TestKeystoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore")
+	ks, err := OpenKeystore(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp := NewKeyPair()
+	ui := NewUserIdentity("alice", kp.Verify(), kp.Sign())
+	if err := ks.Add("alice", ui); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.SetDefault("store", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenKeystore(path, "wrong"); err == nil {
+		t.Error("opened with a wrong passphrase")
+	}
+	ks, err = OpenKeystore(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ks.Get("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Fingerprint() != ui.Fingerprint() {
+		t.Errorf("fingerprint %s, want %s", got.Fingerprint(), ui.Fingerprint())
+	}
+	if ks.Default("store") != "alice" {
+		t.Errorf("default %q, want alice", ks.Default("store"))
+	}
+}
+
+func TestKeystoreIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore")
+	ks, err := OpenKeystore(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := newTestIdentity("alice"), newTestIdentity("bob")
+	if err := ks.Add("alice", alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Add("bob", bob); err != nil {
+		t.Fatal(err)
+	}
+	addr := "bootstrap/title/store"
+	stKey, err := StoreKey(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.SetDefault(stKey, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ui, err := KeystoreIdentity(m, "pass", "alice", addr); err != nil || ui.Fingerprint() != alice.Fingerprint() {
+		t.Errorf("named identity %v, %v, want alice", ui, err)
+	}
+	if ui, err := KeystoreIdentity(m, "pass", "", addr); err != nil || ui.Fingerprint() != bob.Fingerprint() {
+		t.Errorf("default identity %v, %v, want bob", ui, err)
+	}
+	if _, err := KeystoreIdentity(m, "pass", "", "bootstrap/title/other"); err == nil {
+		t.Error("selected one of several identities without a default")
+	}
+	if _, err := KeystoreIdentity(m, "pass", "carol", addr); err == nil {
+		t.Error("found an identity which is not in the keystore")
+	}
+	if _, err := KeystoreIdentity(m, "wrong", "alice", addr); err == nil {
+		t.Error("opened with a wrong passphrase")
+	}
+
+	// an exported identity is the only one of its file
+	buf := &bytes.Buffer{}
+	if err := ExportIdentity(buf, "export", "alice", alice); err != nil {
+		t.Fatal(err)
+	}
+	if ui, err := KeystoreIdentity(buf.Bytes(), "export", "", addr); err != nil || ui.Fingerprint() != alice.Fingerprint() {
+		t.Errorf("exported identity %v, %v, want alice", ui, err)
+	}
+}
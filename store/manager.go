@@ -0,0 +1,96 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// StoreManager owns the loaded stores, so that the GUI and the local API share them.
+// Stores are keyed by StoreHash of their title and store address.
+type StoreManager struct {
+	mutex  sync.Mutex
+	stores map[string]IDocumentStore
+}
+
+func NewStoreManager() *StoreManager {
+	return &StoreManager{stores: make(map[string]IDocumentStore)}
+}
+
+// StoreKey returns the key of the store at addr (bootstrap list address/title/store address).
+func StoreKey(addr string) (string, error) {
+	keys := splitKey(addr)
+	if len(keys) != 3 {
+		return "", errors.New("invalid addr")
+	}
+	return StoreHash(keys[1], keys[2]), nil
+}
+
+// Load returns the loaded store at addr, or loads it.
+func (sm *StoreManager) Load(addr string) (string, IDocumentStore, error) {
+	key, err := StoreKey(addr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if st, ok := sm.stores[key]; ok {
+		return key, st, nil
+	}
+	st, err := LoadDocumentStore(addr, BaseDir(filepath.Join("stores", key)))
+	if err != nil {
+		return "", nil, err
+	}
+	sm.stores[key] = st
+	return key, st, nil
+}
+
+// Set replaces the store of key, closing the previous one.
+func (sm *StoreManager) Set(key string, st IDocumentStore) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if old, ok := sm.stores[key]; ok && old != st {
+		old.Close()
+	}
+	sm.stores[key] = st
+}
+
+// Remove closes the store of key.
+func (sm *StoreManager) Remove(key string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if st, ok := sm.stores[key]; ok {
+		st.Close()
+		delete(sm.stores, key)
+	}
+}
+
+func (sm *StoreManager) Get(key string) (IDocumentStore, bool) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	st, ok := sm.stores[key]
+	return st, ok
+}
+
+// Keys returns the keys of the loaded stores in order.
+func (sm *StoreManager) Keys() []string {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	keys := make([]string, 0, len(sm.stores))
+	for key := range sm.stores {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (sm *StoreManager) Close() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	for key, st := range sm.stores {
+		st.Close()
+		delete(sm.stores, key)
+	}
+}
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+	query "github.com/ipfs/go-datastore/query"
+)
+
+const (
+	mirrorInterval   = time.Second * 10
+	mirrorMinBackoff = time.Second * 10
+	mirrorMaxBackoff = time.Hour
+	// fetched data is checked again, since blocks can be lost or removed
+	mirrorVerifyInterval = time.Hour
+)
+
+type mirrorItem struct {
+	fetched  bool
+	attempts int
+	next     time.Time
+	err      error
+	// when the data was last found complete
+	checked time.Time
+}
+
+// mirror tracks the cids referenced by all document revisions.
+// It is an entryIndexer, so new entries of the signature store are added as they arrive.
+type mirror struct {
+	mutex sync.Mutex
+	items map[string]*mirrorItem
+	wake  chan struct{}
+}
+
+func newMirror() *mirror {
+	return &mirror{
+		items: make(map[string]*mirrorItem),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+func (mr *mirror) put(e query.Entry) {
+	cids := make(map[string]struct{})
+	entryCids(e.Key, e.Value, cids)
+
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	for c := range cids {
+		if _, ok := mr.items[c]; !ok {
+			mr.items[c] = &mirrorItem{}
+		}
+	}
+}
+func (mr *mirror) flush() error {
+	mr.notify()
+	return nil
+}
+func (mr *mirror) notify() {
+	select {
+	case mr.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reset forgets the results, so that all cids are fetched or checked again.
+func (mr *mirror) reset() {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	for c := range mr.items {
+		mr.items[c] = &mirrorItem{}
+	}
+}
+
+// due returns the cids to fetch now, and the fetched ones to check again.
+func (mr *mirror) due() []string {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	now := time.Now()
+	cids := make([]string, 0)
+	for c, item := range mr.items {
+		if item.fetched {
+			if now.Sub(item.checked) >= mirrorVerifyInterval {
+				cids = append(cids, c)
+			}
+		} else if !now.Before(item.next) {
+			cids = append(cids, c)
+		}
+	}
+	sort.Strings(cids)
+	return cids
+}
+
+func (mr *mirror) has(c string) bool {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	_, ok := mr.items[c]
+	return ok
+}
+
+func (mr *mirror) fetched(c string) bool {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	item, ok := mr.items[c]
+	return ok && item.fetched
+}
+
+// done records the result of a fetch. Failures are retried with exponential backoff.
+func (mr *mirror) done(c string, err error) {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	item := mr.items[c]
+	if err == nil {
+		item.fetched = true
+		item.attempts = 0
+		item.err = nil
+		item.checked = time.Now()
+		return
+	}
+	item.fetched = false
+	backoff := mirrorMinBackoff << item.attempts
+	if backoff > mirrorMaxBackoff || backoff <= 0 {
+		backoff = mirrorMaxBackoff
+	}
+	item.attempts++
+	item.next = time.Now().Add(backoff)
+	item.err = err
+}
+
+type MirrorProgress struct {
+	Enabled bool
+	// the number of cids referenced by documents, fetched and failed at the last attempt
+	Cids    int
+	Fetched int
+	Failing int
+	// "cid: error" of the failing cids
+	Errors []string
+}
+
+func (ds *documentStore) MirrorProgress() *MirrorProgress {
+	enabled := ds.Mirroring()
+	ds.mirror.mutex.Lock()
+	defer ds.mirror.mutex.Unlock()
+	mp := &MirrorProgress{
+		Enabled: enabled,
+		Cids:    len(ds.mirror.items),
+		Errors:  make([]string, 0),
+	}
+	for c, item := range ds.mirror.items {
+		if item.fetched {
+			mp.Fetched++
+		} else if item.err != nil {
+			mp.Failing++
+			mp.Errors = append(mp.Errors, c+": "+item.err.Error())
+		}
+	}
+	sort.Strings(mp.Errors)
+	return mp
+}
+
+func (ds *documentStore) Mirroring() bool {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	return ds.pins.mirror
+}
+
+func (ds *documentStore) setMirror(enabled bool) error {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	ds.pins.mirror = enabled
+	return ds.pins.flush()
+}
+
+// StartMirror fetches and keeps the data of every document revision, including new ones.
+// The mirror mode is kept over restarts until StopMirror.
+func (ds *documentStore) StartMirror() error {
+	if err := ds.setMirror(true); err != nil {
+		return err
+	}
+	ds.mirror.notify()
+	return nil
+}
+
+// StopMirror stops fetching, and lets the fetched data be evicted again.
+func (ds *documentStore) StopMirror() error {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	ds.pins.mirror = false
+	ds.pins.mirrored = make(map[string]struct{})
+	ds.mirror.reset()
+	return ds.pins.flush()
+}
+
+// mirrorOnce fetches the due cids, or finds them complete in the local blocks,
+// and holds them from the eviction.
+// Fetched data is fetched again only if some of its blocks are found missing.
+func (ds *documentStore) mirrorOnce() {
+	lb, lbErr := newLocalBlocks(ds.is)
+	held := make([]string, 0)
+	defer func() { ds.pins.holdMirrored(held) }()
+
+	for _, c := range ds.mirror.due() {
+		if ds.ctx.Err() != nil || !ds.Mirroring() {
+			return
+		}
+		// without the local blocks, fetched data is taken as complete
+		complete := ds.mirror.fetched(c)
+		if lbErr == nil {
+			found, ok := lb.dataBlocks(ds.ctx, c, make(map[cid.Cid]struct{}))
+			complete = found && ok
+		}
+		if complete {
+			ds.mirror.done(c, nil)
+			held = append(held, c)
+			continue
+		}
+		_, err := ds.is.GetReader(c, archiveFetchTimeout)
+		ds.mirror.done(c, err)
+		if err == nil {
+			held = append(held, c)
+		}
+	}
+}
+
+func (ds *documentStore) runMirror() {
+	go func() {
+		ticker := time.NewTicker(mirrorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ds.ctx.Done():
+				return
+			case <-ds.mirror.wake:
+			case <-ticker.C:
+			}
+			if ds.Mirroring() {
+				ds.mirrorOnce()
+			}
+		}
+	}()
+}
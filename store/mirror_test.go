@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestMirrorLocalData(t *testing.T) {
+	ds := newTestDocumentStore(t, newTestBootstrap(t), "mirror")
+	ui := newTestIdentity("alice")
+	putTestDocument(t, ds, ui, "doc", "first", "first text")
+	putTestDocument(t, ds, ui, "doc", "second", "second text")
+	if err := ds.watcher.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.setMirror(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// both revisions and the previous one are local, so nothing is fetched
+	ds.mirrorOnce()
+	mp := ds.MirrorProgress()
+	if mp.Cids != 3 || mp.Fetched != mp.Cids {
+		t.Fatalf("fetched %d of %d cids, want 3 of 3", mp.Fetched, mp.Cids)
+	}
+	ds.pins.mutex.Lock()
+	held := len(ds.pins.mirrored)
+	ds.pins.mutex.Unlock()
+	if held != mp.Cids {
+		t.Errorf("%d cids held, want %d", held, mp.Cids)
+	}
+
+	// the check after mirrorVerifyInterval finds them complete again
+	ds.mirror.mutex.Lock()
+	for _, item := range ds.mirror.items {
+		item.checked = item.checked.Add(-mirrorVerifyInterval)
+	}
+	ds.mirror.mutex.Unlock()
+	if due := ds.mirror.due(); len(due) != mp.Cids {
+		t.Fatalf("%d cids due, want %d", len(due), mp.Cids)
+	}
+	ds.mirrorOnce()
+	if due := ds.mirror.due(); len(due) != 0 {
+		t.Errorf("%v are due after the check", due)
+	}
+	if mp := ds.MirrorProgress(); mp.Fetched != mp.Cids || mp.Failing != 0 {
+		t.Errorf("fetched %d of %d cids, %d failing after the check", mp.Fetched, mp.Cids, mp.Failing)
+	}
+}
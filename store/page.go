@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+// a cursor points to the start of a page.
+// A page starts after the document with the key After.
+// Offset is used only when that document is no longer in the results.
+type cursor struct {
+	After  string
+	Offset int
+}
+
+func (c *cursor) encode() string {
+	mc := &pb.Cursor{
+		After:  c.After,
+		Offset: int64(c.Offset),
+	}
+	m, _ := proto.Marshal(mc)
+	return base64.RawURLEncoding.EncodeToString(m)
+}
+func decodeCursor(str string) (*cursor, error) {
+	if str == "" {
+		return &cursor{}, nil
+	}
+	m, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	mc := &pb.Cursor{}
+	if err := proto.Unmarshal(m, mc); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	if mc.GetOffset() < 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	return &cursor{mc.GetAfter(), int(mc.GetOffset())}, nil
+}
+
+func (c *cursor) start(ndocs []*NamedDocument) int {
+	if c.After != "" {
+		for idx, ndoc := range ndocs {
+			if ndoc.Name == c.After {
+				return idx + 1
+			}
+		}
+	}
+	if c.Offset > len(ndocs) {
+		return len(ndocs)
+	}
+	return c.Offset
+}
+
+func cursorAt(ndocs []*NamedDocument, start int) string {
+	c := &cursor{Offset: start}
+	if start > 0 {
+		c.After = ndocs[start-1].Name
+	}
+	return c.encode()
+}
+
+// Page is a page of query results.
+// Cursor fetches this page again; Next and Prev are "" at the last and the first page.
+type Page struct {
+	Docs   []*NamedDocument
+	Cursor string
+	Next   string
+	Prev   string
+	// index of the first document in the results
+	Offset int
+}
+
+// QueryPage returns size documents from the position of cur ("" for the first page).
+// Pages are anchored to a document rather than an offset,
+// so documents synced in the meantime do not shift the following pages.
+func (ds *documentStore) QueryPage(cur string, size int, qs ...query.Query) (*Page, error) {
+	if size <= 0 {
+		return nil, errors.New("invalid page size")
+	}
+	c, err := decodeCursor(strings.TrimSpace(cur))
+	if err != nil {
+		return nil, err
+	}
+	ndocs, err := ds.queryDocuments(qs...)
+	if err != nil {
+		return nil, err
+	}
+
+	start := c.start(ndocs)
+	end := start + size
+	if end > len(ndocs) {
+		end = len(ndocs)
+	}
+	for _, ndoc := range ndocs[start:end] {
+		ds.setSigner(ndoc)
+	}
+	page := &Page{
+		Docs:   ndocs[start:end],
+		Cursor: cursorAt(ndocs, start),
+		Offset: start,
+	}
+	if end < len(ndocs) {
+		page.Next = cursorAt(ndocs, end)
+	}
+	if start > 0 {
+		prev := start - size
+		if prev < 0 {
+			prev = 0
+		}
+		page.Prev = cursorAt(ndocs, prev)
+	}
+	return page, nil
+}
@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        v3.19.4
+// source: docindex.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DocIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Docs    []*DocIndexDoc `protobuf:"bytes,1,rep,name=docs,proto3" json:"docs,omitempty"`
+	Entries []string       `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *DocIndex) Reset() {
+	*x = DocIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_docindex_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DocIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocIndex) ProtoMessage() {}
+
+func (x *DocIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_docindex_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocIndex.ProtoReflect.Descriptor instead.
+func (*DocIndex) Descriptor() ([]byte, []int) {
+	return file_docindex_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DocIndex) GetDocs() []*DocIndexDoc {
+	if x != nil {
+		return x.Docs
+	}
+	return nil
+}
+
+func (x *DocIndex) GetEntries() []string {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type DocIndexDoc struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Revision int64    `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	Tags     []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Types    []string `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`
+	Time     []byte   `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *DocIndexDoc) Reset() {
+	*x = DocIndexDoc{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_docindex_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DocIndexDoc) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocIndexDoc) ProtoMessage() {}
+
+func (x *DocIndexDoc) ProtoReflect() protoreflect.Message {
+	mi := &file_docindex_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocIndexDoc.ProtoReflect.Descriptor instead.
+func (*DocIndexDoc) Descriptor() ([]byte, []int) {
+	return file_docindex_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DocIndexDoc) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *DocIndexDoc) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *DocIndexDoc) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *DocIndexDoc) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *DocIndexDoc) GetTime() []byte {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_docindex_proto protoreflect.FileDescriptor
+
+var file_docindex_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x64, 0x6f, 0x63, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x62, 0x22, 0x4f, 0x0a, 0x08, 0x44, 0x6f,
+	0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x29, 0x0a, 0x04, 0x64, 0x6f, 0x63, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x62, 0x2e,
+	0x44, 0x6f, 0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x44, 0x6f, 0x63, 0x52, 0x04, 0x64, 0x6f, 0x63,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x79, 0x0a, 0x0b, 0x44,
+	0x6f, 0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x44, 0x6f, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08,
+	0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_docindex_proto_rawDescOnce sync.Once
+	file_docindex_proto_rawDescData = file_docindex_proto_rawDesc
+)
+
+func file_docindex_proto_rawDescGZIP() []byte {
+	file_docindex_proto_rawDescOnce.Do(func() {
+		file_docindex_proto_rawDescData = protoimpl.X.CompressGZIP(file_docindex_proto_rawDescData)
+	})
+	return file_docindex_proto_rawDescData
+}
+
+var file_docindex_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_docindex_proto_goTypes = []interface{}{
+	(*DocIndex)(nil),    // 0: store.pb.DocIndex
+	(*DocIndexDoc)(nil), // 1: store.pb.DocIndexDoc
+}
+var file_docindex_proto_depIdxs = []int32{
+	1, // 0: store.pb.DocIndex.docs:type_name -> store.pb.DocIndexDoc
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_docindex_proto_init() }
+func file_docindex_proto_init() {
+	if File_docindex_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_docindex_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DocIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_docindex_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DocIndexDoc); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_docindex_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_docindex_proto_goTypes,
+		DependencyIndexes: file_docindex_proto_depIdxs,
+		MessageInfos:      file_docindex_proto_msgTypes,
+	}.Build()
+	File_docindex_proto = out.File
+	file_docindex_proto_rawDesc = nil
+	file_docindex_proto_goTypes = nil
+	file_docindex_proto_depIdxs = nil
+}
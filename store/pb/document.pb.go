@@ -25,12 +25,14 @@ type Document struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Cids   []*TypedCid `protobuf:"bytes,1,rep,name=cids,proto3" json:"cids,omitempty"`
-	Title  string      `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Time   []byte      `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
-	Types  []string    `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`
-	Tags   []string    `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
-	Dscrpt string      `protobuf:"bytes,6,opt,name=dscrpt,proto3" json:"dscrpt,omitempty"`
+	Cids     []*TypedCid `protobuf:"bytes,1,rep,name=cids,proto3" json:"cids,omitempty"`
+	Title    string      `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Time     []byte      `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	Types    []string    `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`
+	Tags     []string    `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Dscrpt   string      `protobuf:"bytes,6,opt,name=dscrpt,proto3" json:"dscrpt,omitempty"`
+	Prev     string      `protobuf:"bytes,7,opt,name=prev,proto3" json:"prev,omitempty"`
+	Revision int64       `protobuf:"varint,8,opt,name=revision,proto3" json:"revision,omitempty"`
 }
 
 func (x *Document) Reset() {
@@ -107,6 +109,20 @@ func (x *Document) GetDscrpt() string {
 	return ""
 }
 
+func (x *Document) GetPrev() string {
+	if x != nil {
+		return x.Prev
+	}
+	return ""
+}
+
+func (x *Document) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
 type TypedCid struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -114,6 +130,7 @@ type TypedCid struct {
 
 	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
 	Cid  string `protobuf:"bytes,2,opt,name=cid,proto3" json:"cid,omitempty"`
+	Size int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
 }
 
 func (x *TypedCid) Reset() {
@@ -162,11 +179,18 @@ func (x *TypedCid) GetCid() string {
 	return ""
 }
 
+func (x *TypedCid) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 var File_document_proto protoreflect.FileDescriptor
 
 var file_document_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x62, 0x22, 0x9e, 0x01, 0x0a, 0x08, 0x44,
+	0x12, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x62, 0x22, 0xce, 0x01, 0x0a, 0x08, 0x44,
 	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x63, 0x69, 0x64, 0x73, 0x18,
 	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x62,
 	0x2e, 0x54, 0x79, 0x70, 0x65, 0x64, 0x43, 0x69, 0x64, 0x52, 0x04, 0x63, 0x69, 0x64, 0x73, 0x12,
@@ -176,11 +200,16 @@ var file_document_proto_rawDesc = []byte{
 	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x79, 0x70, 0x65, 0x73, 0x12,
 	0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74,
 	0x61, 0x67, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x73, 0x63, 0x72, 0x70, 0x74, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x73, 0x63, 0x72, 0x70, 0x74, 0x22, 0x30, 0x0a, 0x08, 0x54,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x73, 0x63, 0x72, 0x70, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x72, 0x65, 0x76, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x72, 0x65, 0x76, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x44, 0x0a, 0x08, 0x54,
 	0x79, 0x70, 0x65, 0x64, 0x43, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x63,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x69, 0x64, 0x42, 0x06, 0x5a,
-	0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
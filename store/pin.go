@@ -0,0 +1,510 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+)
+
+const quotaInterval = time.Minute
+
+type PinInfo struct {
+	Key string
+	// the data of the latest revision when the pin was last resolved
+	Cids []string
+	Time time.Time
+	// size of the local blocks of the cids
+	Size int64
+}
+
+// pinManager keeps the pinned documents, the disk quota and the last access time of each cid.
+// It is persisted under the store's base dir (path == "" keeps it in memory only).
+type pinManager struct {
+	mutex    sync.Mutex
+	path     string
+	pins     map[string]*PinInfo
+	quota    int64
+	accessed map[string]time.Time
+	mirror   bool
+	// cids fetched by the mirror, kept from the eviction until StopMirror
+	mirrored map[string]struct{}
+	// held for reading while data is added, so that gc does not remove blocks of a half-added data
+	adding sync.RWMutex
+	// held while the data is evicted, so that SetQuota, the ticker and the GUI do not evict at once
+	evicting sync.Mutex
+}
+
+func newPinManager(path string) *pinManager {
+	pm := &pinManager{
+		path:     path,
+		pins:     make(map[string]*PinInfo),
+		accessed: make(map[string]time.Time),
+		mirrored: make(map[string]struct{}),
+	}
+	if err := pm.load(); err != nil {
+		pm.pins = make(map[string]*PinInfo)
+		pm.accessed = make(map[string]time.Time)
+		pm.mirrored = make(map[string]struct{})
+	}
+	return pm
+}
+
+func (pm *pinManager) load() error {
+	if pm.path == "" {
+		return nil
+	}
+	m, err := os.ReadFile(pm.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	mps := &pb.Pins{}
+	if err := proto.Unmarshal(m, mps); err != nil {
+		return err
+	}
+
+	for _, mp := range mps.GetPins() {
+		t := time.Time{}
+		if err := t.UnmarshalBinary(mp.GetTime()); err != nil {
+			return err
+		}
+		pm.pins[mp.GetKey()] = &PinInfo{mp.GetKey(), mp.GetCids(), t, 0}
+	}
+	pm.quota = mps.GetQuota()
+	pm.mirror = mps.GetMirror()
+	for c, t := range mps.GetAccessed() {
+		pm.accessed[c] = time.Unix(0, t)
+	}
+	for _, c := range mps.GetMirrored() {
+		pm.mirrored[c] = struct{}{}
+	}
+	return nil
+}
+
+// flush must be called with the lock held.
+func (pm *pinManager) flush() error {
+	if pm.path == "" {
+		return nil
+	}
+	mps := &pb.Pins{
+		Pins:     make([]*pb.Pin, 0, len(pm.pins)),
+		Quota:    pm.quota,
+		Accessed: make(map[string]int64, len(pm.accessed)),
+		Mirror:   pm.mirror,
+		Mirrored: mapToSlice(pm.mirrored),
+	}
+	for _, pin := range pm.pins {
+		mt, _ := pin.Time.MarshalBinary()
+		mps.Pins = append(mps.Pins, &pb.Pin{
+			Key:  pin.Key,
+			Cids: pin.Cids,
+			Time: mt,
+		})
+	}
+	for c, t := range pm.accessed {
+		mps.Accessed[c] = t.UnixNano()
+	}
+	m, err := proto.Marshal(mps)
+	if err != nil {
+		return err
+	}
+	return writeFile(pm.path, m)
+}
+
+// access times are saved with the next pin change or eviction.
+func (pm *pinManager) touch(c string) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.accessed[c] = time.Now()
+}
+
+// pruneAccessed forgets the access times of the cids which are not known,
+// except recent ones, e.g. the data of an upload which is not put yet.
+// It must be called with the lock held.
+func (pm *pinManager) pruneAccessed(known func(string) bool) {
+	for c, t := range pm.accessed {
+		if time.Since(t) >= gcGracePeriod && !known(c) {
+			delete(pm.accessed, c)
+		}
+	}
+}
+
+// resolvePins returns the pinned documents with the cids of their latest revisions.
+// A pin keeps its last resolved cids while its document is not found, e.g. before the store is synced.
+func (ds *documentStore) resolvePins() []*PinInfo {
+	ds.pins.mutex.Lock()
+	pins := make([]*PinInfo, 0, len(ds.pins.pins))
+	for _, pin := range ds.pins.pins {
+		pins = append(pins, &PinInfo{pin.Key, pin.Cids, pin.Time, 0})
+	}
+	ds.pins.mutex.Unlock()
+
+	for _, pin := range pins {
+		doc, _, err := ds.latestRevision(pin.Key)
+		if err != nil {
+			continue
+		}
+		pin.Cids = make([]string, len(doc.Cids))
+		for idx, tc := range doc.Cids {
+			pin.Cids[idx] = tc.Cid
+		}
+	}
+
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	for _, pin := range pins {
+		if cur, ok := ds.pins.pins[pin.Key]; ok {
+			cur.Cids = pin.Cids
+		}
+	}
+	sort.Slice(pins, func(i, j int) bool { return pins[i].Key < pins[j].Key })
+	return pins
+}
+
+// pinnedCids returns the cids of the latest revisions of the pinned documents and the ones held by the mirror.
+func (ds *documentStore) pinnedCids() map[string]struct{} {
+	cids := make(map[string]struct{})
+	for _, pin := range ds.resolvePins() {
+		for _, c := range pin.Cids {
+			cids[c] = struct{}{}
+		}
+	}
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	for c := range ds.pins.mirrored {
+		cids[c] = struct{}{}
+	}
+	return cids
+}
+
+// holdMirrored keeps the cids fetched by the mirror like pinned ones.
+func (pm *pinManager) holdMirrored(cids []string) error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	// the mirror was stopped meanwhile
+	if !pm.mirror {
+		return nil
+	}
+	changed := false
+	for _, c := range cids {
+		if _, ok := pm.mirrored[c]; !ok {
+			pm.mirrored[c] = struct{}{}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return pm.flush()
+}
+
+// trackedIpfs records when each cid was last used, for the LRU eviction.
+type trackedIpfs struct {
+	ipfs.Ipfs
+	pm *pinManager
+}
+
+func (ti trackedIpfs) AddReader(r io.Reader, timeouts ...time.Duration) (string, error) {
+	ti.pm.adding.RLock()
+	defer ti.pm.adding.RUnlock()
+	c, err := ti.Ipfs.AddReader(r, timeouts...)
+	if err == nil {
+		ti.pm.touch(c)
+	}
+	return c, err
+}
+func (ti trackedIpfs) Add(data []byte, timeouts ...time.Duration) (string, error) {
+	ti.pm.adding.RLock()
+	defer ti.pm.adding.RUnlock()
+	c, err := ti.Ipfs.Add(data, timeouts...)
+	if err == nil {
+		ti.pm.touch(c)
+	}
+	return c, err
+}
+func (ti trackedIpfs) GetReader(c string, timeouts ...time.Duration) (io.Reader, error) {
+	r, err := ti.Ipfs.GetReader(c, timeouts...)
+	if err == nil {
+		ti.pm.touch(c)
+	}
+	return r, err
+}
+func (ti trackedIpfs) Get(c string, timeouts ...time.Duration) ([]byte, error) {
+	m, err := ti.Ipfs.Get(c, timeouts...)
+	if err == nil {
+		ti.pm.touch(c)
+	}
+	return m, err
+}
+
+// Pin keeps all data of the latest revision of the document from the eviction,
+// following its new revisions. The data is fetched if it is not local,
+// and the data of new revisions is fetched with the next quota check.
+func (ds *documentStore) Pin(key string) error {
+	if len(splitKey(key)) != 3 {
+		return errors.New("invalid document key")
+	}
+	doc, _, err := ds.latestRevision(key)
+	if err != nil {
+		return err
+	}
+
+	cids := make([]string, len(doc.Cids))
+	for idx, tc := range doc.Cids {
+		if _, err := ds.Ipfs().GetReader(tc.Cid, archiveFetchTimeout); err != nil {
+			return err
+		}
+		cids[idx] = tc.Cid
+	}
+
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	ds.pins.pins[key] = &PinInfo{key, cids, time.Now().UTC(), 0}
+	return ds.pins.flush()
+}
+
+func (ds *documentStore) Unpin(key string) error {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	if _, ok := ds.pins.pins[key]; !ok {
+		return errors.New("not pinned")
+	}
+	delete(ds.pins.pins, key)
+	return ds.pins.flush()
+}
+
+func (ds *documentStore) IsPinned(key string) bool {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	_, ok := ds.pins.pins[key]
+	return ok
+}
+
+// Pins returns the pinned documents sorted by key.
+func (ds *documentStore) Pins() ([]*PinInfo, error) {
+	pins := ds.resolvePins()
+	lb, err := newLocalBlocks(ds.is)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ds.ctx, archiveFetchTimeout)
+	defer cancel()
+	for _, pin := range pins {
+		blocks := make(map[cid.Cid]struct{})
+		for _, c := range pin.Cids {
+			lb.dataBlocks(ctx, c, blocks)
+		}
+		pin.Size = lb.size(ctx, blocks)
+	}
+	return pins, nil
+}
+
+// Quota is the maximum size of the local ipfs blocks in bytes (0: no limit).
+func (ds *documentStore) Quota() int64 {
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	return ds.pins.quota
+}
+func (ds *documentStore) SetQuota(quota int64) error {
+	if quota < 0 {
+		return errors.New("invalid quota")
+	}
+	ds.pins.mutex.Lock()
+	ds.pins.quota = quota
+	err := ds.pins.flush()
+	ds.pins.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	go ds.EnforceQuota()
+	return nil
+}
+
+// DiskUsage returns the total size of the local ipfs blocks.
+func (ds *documentStore) DiskUsage() (int64, error) {
+	lb, err := newLocalBlocks(ds.is)
+	if err != nil {
+		return 0, err
+	}
+	sizes, err := lb.all(ds.ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := int64(0)
+	for _, size := range sizes {
+		total += size
+	}
+	return total, nil
+}
+
+// documentCids returns the cids referenced by all document revisions in the signature store.
+func (ds *documentStore) documentCids() ([]string, error) {
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return nil, err
+	}
+	cids := make(map[string]struct{})
+	for res := range rs.Next() {
+		if res.Error != nil {
+			continue
+		}
+		entryCids(res.Key, res.Value, cids)
+	}
+	return mapToSlice(cids), nil
+}
+
+// EnforceQuota evicts the data of unpinned documents, least recently used first,
+// until the local blocks fit in the quota. It returns the freed size.
+// Blocks which belong to no document are not evicted,
+// and nothing is evicted while a pinned data is only partly local.
+// Data fetched by the mirror is kept like pinned one.
+func (ds *documentStore) EnforceQuota() (int64, error) {
+	ds.pins.evicting.Lock()
+	defer ds.pins.evicting.Unlock()
+	quota := ds.Quota()
+	if quota == 0 {
+		return 0, nil
+	}
+	lb, err := newLocalBlocks(ds.is)
+	if err != nil {
+		return 0, err
+	}
+	sizes, err := lb.all(ds.ctx)
+	if err != nil {
+		return 0, err
+	}
+	usage := int64(0)
+	for _, size := range sizes {
+		usage += size
+	}
+	if usage <= quota {
+		return 0, nil
+	}
+
+	// blocks under a missing one of a pinned data are unknown,
+	// and a candidate may share them, so nothing is evicted then.
+	pinnedCids := ds.pinnedCids()
+	pinned := make(map[cid.Cid]struct{})
+	for c := range pinnedCids {
+		if found, complete := lb.dataBlocks(ds.ctx, c, pinned); found && !complete {
+			return 0, errors.New("pinned data is only partly local: " + c)
+		}
+	}
+
+	roots, err := ds.documentCids()
+	if err != nil {
+		return 0, err
+	}
+	candidates := make([]string, 0, len(roots))
+	for _, c := range roots {
+		if _, ok := pinnedCids[c]; !ok {
+			candidates = append(candidates, c)
+		}
+	}
+	ds.pins.mutex.Lock()
+	accessed := ds.pins.accessed
+	sort.Slice(candidates, func(i, j int) bool {
+		return accessed[candidates[i]].Before(accessed[candidates[j]])
+	})
+	ds.pins.mutex.Unlock()
+
+	freed := int64(0)
+	for _, c := range candidates {
+		if usage-freed <= quota {
+			break
+		}
+		blocks := make(map[cid.Cid]struct{})
+		lb.dataBlocks(ds.ctx, c, blocks)
+		for b := range blocks {
+			if _, ok := pinned[b]; ok {
+				continue
+			}
+			size, ok := sizes[b]
+			if !ok {
+				continue
+			}
+			if err := lb.remove(ds.ctx, b); err != nil {
+				continue
+			}
+			delete(sizes, b)
+			freed += size
+		}
+	}
+
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	return freed, ds.pins.flush()
+}
+
+// fetchPinned fetches the data of the pinned documents which is not complete in the local blocks,
+// e.g. the data of a revision put after Pin.
+func (ds *documentStore) fetchPinned() {
+	lb, err := newLocalBlocks(ds.is)
+	if err != nil {
+		return
+	}
+	for _, pin := range ds.resolvePins() {
+		for _, c := range pin.Cids {
+			if ds.ctx.Err() != nil {
+				return
+			}
+			if found, complete := lb.dataBlocks(ds.ctx, c, make(map[cid.Cid]struct{})); found && complete {
+				continue
+			}
+			ds.Ipfs().GetReader(c, archiveFetchTimeout)
+		}
+	}
+}
+
+// pruneAccessed forgets the access times of the cids which no document revision references,
+// so that they do not pile up in the pin file.
+// The mirror tracks the cids of all revisions, whether mirroring or not.
+func (ds *documentStore) pruneAccessed() error {
+	if ds.watcher.behind() {
+		return nil
+	}
+	pinned := ds.pinnedCids()
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	n := len(ds.pins.accessed)
+	ds.pins.pruneAccessed(func(c string) bool {
+		if _, ok := pinned[c]; ok {
+			return true
+		}
+		return ds.mirror.has(c)
+	})
+	if len(ds.pins.accessed) == n {
+		return nil
+	}
+	return ds.pins.flush()
+}
+
+func (ds *documentStore) runQuota() {
+	go func() {
+		ticker := time.NewTicker(quotaInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ds.ctx.Done():
+				return
+			case <-ticker.C:
+				ds.fetchPinned()
+				ds.pruneAccessed()
+				ds.EnforceQuota()
+			}
+		}
+	}()
+}
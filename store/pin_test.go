@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPinFollowsRevisions(t *testing.T) {
+	ds := newTestDocumentStore(t, newTestBootstrap(t), "pin")
+	ui := newTestIdentity("alice")
+	key := putTestDocument(t, ds, ui, "doc", "first", "first text")
+	if err := ds.Pin(key); err != nil {
+		t.Fatal(err)
+	}
+	putTestDocument(t, ds, ui, "doc", "second", "second text")
+	if err := ds.watcher.poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, _, err := ds.latestRevision(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := ds.pinnedCids()
+	for _, tc := range doc.Cids {
+		if _, ok := pinned[tc.Cid]; !ok {
+			t.Errorf("%s of the latest revision is not pinned", tc.Cid)
+		}
+	}
+
+	// the access times of unknown cids are forgotten, except recent ones
+	ds.pins.mutex.Lock()
+	ds.pins.accessed["unknown"] = time.Now().Add(-gcGracePeriod)
+	ds.pins.accessed["recent"] = time.Now()
+	ds.pins.accessed[doc.Cids[0].Cid] = time.Now().Add(-gcGracePeriod)
+	ds.pins.mutex.Unlock()
+	if err := ds.pruneAccessed(); err != nil {
+		t.Fatal(err)
+	}
+	ds.pins.mutex.Lock()
+	defer ds.pins.mutex.Unlock()
+	if _, ok := ds.pins.accessed["unknown"]; ok {
+		t.Error("an unknown cid is kept")
+	}
+	for _, c := range []string{"recent", doc.Cids[0].Cid} {
+		if _, ok := ds.pins.accessed[c]; !ok {
+			t.Errorf("%s is forgotten", c)
+		}
+	}
+}
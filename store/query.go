@@ -1,6 +1,7 @@
 package store
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,97 @@ func sliceToMap(slc []string) map[string]struct{} {
 	return mp
 }
 
+// DocumentEntry is a query entry with its document decoded.
+// Query decodes each entry only once and passes it to DocumentFilters and DocumentOrders.
+type DocumentEntry struct {
+	query.Entry
+	*Document
+}
+
+func newDocumentEntry(e query.Entry) (*DocumentEntry, error) {
+	d := newEmptyDocument()
+	if err := d.Unmarshal(e.Value); err != nil {
+		return nil, err
+	}
+	return &DocumentEntry{e, d}, nil
+}
+
+// DocumentFilter is a query.Filter which also works on decoded documents.
+// Any other query.Filter is applied to the raw entry.
+type DocumentFilter interface {
+	query.Filter
+	FilterDocument(*DocumentEntry) bool
+}
+
+// DocumentOrder is a query.Order which also works on decoded documents.
+// Any other query.Order is applied to the raw entries.
+type DocumentOrder interface {
+	query.Order
+	CompareDocuments(a, b *DocumentEntry) int
+}
+
+func filterDocument(f query.Filter, de *DocumentEntry) bool {
+	if df, ok := f.(DocumentFilter); ok {
+		return df.FilterDocument(de)
+	}
+	return f.Filter(de.Entry)
+}
+func compareDocuments(o query.Order, a, b *DocumentEntry) int {
+	if do, ok := o.(DocumentOrder); ok {
+		return do.CompareDocuments(a, b)
+	}
+	return o.Compare(a.Entry, b.Entry)
+}
+
+// applyDocumentQuery works like query.NaiveQueryApply,
+// but entries which are not documents are dropped.
+func applyDocumentQuery(q query.Query, es []query.Entry) []*DocumentEntry {
+	prefix := strings.TrimSuffix(q.Prefix, "/")
+	des := make([]*DocumentEntry, 0, len(es))
+	for _, e := range es {
+		if prefix != "" && !strings.HasPrefix(e.Key, prefix+"/") {
+			continue
+		}
+		de, err := newDocumentEntry(e)
+		if err != nil {
+			continue
+		}
+
+		ok := true
+		for _, f := range q.Filters {
+			if !filterDocument(f, de) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			des = append(des, de)
+		}
+	}
+
+	// the key breaks ties, and orders entries without Orders, since they come
+	// from the index in map order. A total order keeps pages stable.
+	sort.SliceStable(des, func(i, j int) bool {
+		for _, o := range q.Orders {
+			if c := compareDocuments(o, des[i], des[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return des[i].Key < des[j].Key
+	})
+
+	if q.Offset > 0 {
+		if q.Offset >= len(des) {
+			return des[:0]
+		}
+		des = des[q.Offset:]
+	}
+	if q.Limit > 0 && q.Limit < len(des) {
+		des = des[:q.Limit]
+	}
+	return des
+}
+
 //a<b:-1, a==b:0, a>b:1
 //x0 < x1 < x2 < ... < xn
 type TimeOrder struct {
@@ -22,15 +114,17 @@ type TimeOrder struct {
 }
 
 func (o TimeOrder) Compare(a, b query.Entry) int {
-	da := newEmptyDocument()
-	if err := da.Unmarshal(a.Value); err != nil {
+	da, err := newDocumentEntry(a)
+	if err != nil {
 		return 1
 	}
-	db := newEmptyDocument()
-	if err := db.Unmarshal(b.Value); err != nil {
+	db, err := newDocumentEntry(b)
+	if err != nil {
 		return -1
 	}
-
+	return o.CompareDocuments(da, db)
+}
+func (o TimeOrder) CompareDocuments(da, db *DocumentEntry) int {
 	if da.Time.Equal(db.Time) {
 		return 0
 	}
@@ -54,11 +148,13 @@ type CidsFilter struct {
 }
 
 func (f CidsFilter) Filter(e query.Entry) bool {
-	d := newEmptyDocument()
-	if err := d.Unmarshal(e.Value); err != nil {
+	d, err := newDocumentEntry(e)
+	if err != nil {
 		return false
 	}
-
+	return f.FilterDocument(d)
+}
+func (f CidsFilter) FilterDocument(d *DocumentEntry) bool {
 	docCids := make([]string, len(d.Cids))
 	for idx, tc := range d.Cids {
 		docCids[idx] = tc.Cid
@@ -77,10 +173,13 @@ type TitleFilter struct {
 }
 
 func (f TitleFilter) Filter(e query.Entry) bool {
-	d := newEmptyDocument()
-	if err := d.Unmarshal(e.Value); err != nil {
+	d, err := newDocumentEntry(e)
+	if err != nil {
 		return false
 	}
+	return f.FilterDocument(d)
+}
+func (f TitleFilter) FilterDocument(d *DocumentEntry) bool {
 	return strings.Contains(d.Title, f.Title)
 }
 
@@ -89,11 +188,13 @@ type DocTypesFilter struct {
 }
 
 func (f DocTypesFilter) Filter(e query.Entry) bool {
-	d := newEmptyDocument()
-	if err := d.Unmarshal(e.Value); err != nil {
+	d, err := newDocumentEntry(e)
+	if err != nil {
 		return false
 	}
-
+	return f.FilterDocument(d)
+}
+func (f DocTypesFilter) FilterDocument(d *DocumentEntry) bool {
 	docTypeMap := sliceToMap(d.DocTypes)
 	for _, docType := range f.DocTypes {
 		if _, ok := docTypeMap[docType]; !ok {
@@ -108,11 +209,13 @@ type TimeFilter struct {
 }
 
 func (f TimeFilter) Filter(e query.Entry) bool {
-	d := newEmptyDocument()
-	if err := d.Unmarshal(e.Value); err != nil {
+	d, err := newDocumentEntry(e)
+	if err != nil {
 		return false
 	}
-
+	return f.FilterDocument(d)
+}
+func (f TimeFilter) FilterDocument(d *DocumentEntry) bool {
 	return (f.Begin.Before(d.Time) || f.Begin.Equal(d.Time)) && f.End.After(d.Time)
 }
 
@@ -121,11 +224,13 @@ type TagsFilter struct {
 }
 
 func (f TagsFilter) Filter(e query.Entry) bool {
-	d := newEmptyDocument()
-	if err := d.Unmarshal(e.Value); err != nil {
+	d, err := newDocumentEntry(e)
+	if err != nil {
 		return false
 	}
-
+	return f.FilterDocument(d)
+}
+func (f TagsFilter) FilterDocument(d *DocumentEntry) bool {
 	tagsMap := sliceToMap(d.Tags)
 	for _, fTag := range f.Tags {
 		if _, ok := tagsMap[fTag]; !ok {
@@ -134,3 +239,70 @@ func (f TagsFilter) Filter(e query.Entry) bool {
 	}
 	return true
 }
+
+type AuthorFilter struct {
+	// username, pid or pid/username
+	Author string
+}
+
+func (f AuthorFilter) Filter(e query.Entry) bool {
+	// e.Key: pid/username/docname
+	keys := splitKey(e.Key)
+	if len(keys) != 3 {
+		return false
+	}
+	return keys[0] == f.Author || keys[1] == f.Author || keys[0]+"/"+keys[1] == f.Author
+}
+
+type AndFilter struct {
+	Filters []query.Filter
+}
+
+func (f AndFilter) Filter(e query.Entry) bool {
+	for _, sub := range f.Filters {
+		if !sub.Filter(e) {
+			return false
+		}
+	}
+	return true
+}
+func (f AndFilter) FilterDocument(d *DocumentEntry) bool {
+	for _, sub := range f.Filters {
+		if !filterDocument(sub, d) {
+			return false
+		}
+	}
+	return true
+}
+
+type OrFilter struct {
+	Filters []query.Filter
+}
+
+func (f OrFilter) Filter(e query.Entry) bool {
+	for _, sub := range f.Filters {
+		if sub.Filter(e) {
+			return true
+		}
+	}
+	return false
+}
+func (f OrFilter) FilterDocument(d *DocumentEntry) bool {
+	for _, sub := range f.Filters {
+		if filterDocument(sub, d) {
+			return true
+		}
+	}
+	return false
+}
+
+type NotFilter struct {
+	Negated query.Filter
+}
+
+func (f NotFilter) Filter(e query.Entry) bool {
+	return !f.Negated.Filter(e)
+}
+func (f NotFilter) FilterDocument(d *DocumentEntry) bool {
+	return !filterDocument(f.Negated, d)
+}
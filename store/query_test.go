@@ -0,0 +1,104 @@
+package store
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+const benchEntries = 30000
+
+// benchmarkEntries makes n document entries with a few tags each,
+// in the key layout of the signature store (/pid/username/docname).
+func benchmarkEntries(n int) []query.Entry {
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	es := make([]query.Entry, n)
+	for idx := range es {
+		tags := map[string]struct{}{
+			fmt.Sprintf("tag%d", idx%10):  {},
+			fmt.Sprintf("tag%d", idx%100): {},
+		}
+		docTypes := map[string]struct{}{"text": {}}
+		// times are shuffled against the key order, and every 4 documents share one
+		t := base.Add(time.Duration(idx*7919%n/4) * time.Minute)
+		info := NewDocumentInfo(fmt.Sprintf("title %d", idx), "description", docTypes, tags, t)
+		doc := newDocument(info, typedCid{"text", fmt.Sprintf("cid%d", idx), 1024})
+		key := fmt.Sprintf("/pid%d/user%d/doc%d", idx%50, idx%50, idx)
+		es[idx] = query.Entry{Key: key, Value: doc.Marshal()}
+	}
+	return es
+}
+
+func benchmarkQuery(tags []string, orders []query.Order) query.Query {
+	return query.Query{
+		Filters: []query.Filter{documentFilter{}, TagsFilter{tags}},
+		Orders:  orders,
+		Limit:   20,
+	}
+}
+
+func benchmarkNaive(b *testing.B, q query.Query) {
+	es := benchmarkEntries(benchEntries)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rs := query.NaiveQueryApply(q, query.ResultsWithEntries(q, es))
+		if _, err := rs.Rest(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkApply(b *testing.B, q query.Query) {
+	es := benchmarkEntries(benchEntries)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		applyDocumentQuery(q, es)
+	}
+}
+
+func BenchmarkNaiveQueryApply(b *testing.B) {
+	benchmarkNaive(b, benchmarkQuery([]string{"tag3"}, nil))
+}
+func BenchmarkApplyDocumentQuery(b *testing.B) {
+	benchmarkApply(b, benchmarkQuery([]string{"tag3"}, nil))
+}
+func BenchmarkNaiveQueryApplyTimeOrder(b *testing.B) {
+	benchmarkNaive(b, benchmarkQuery([]string{"tag3"}, []query.Order{TimeOrder{true}}))
+}
+func BenchmarkApplyDocumentQueryTimeOrder(b *testing.B) {
+	benchmarkApply(b, benchmarkQuery([]string{"tag3"}, []query.Order{TimeOrder{true}}))
+}
+
+func TestApplyDocumentQueryKeyOrder(t *testing.T) {
+	es := benchmarkEntries(100)
+	// entries from the index come in map order
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(es), func(i, j int) { es[i], es[j] = es[j], es[i] })
+
+	tests := []struct {
+		name   string
+		orders []query.Order
+	}{
+		{"no orders", nil},
+		{"time order", []query.Order{TimeOrder{true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := query.Query{Filters: []query.Filter{documentFilter{}}, Orders: tt.orders}
+			want := applyDocumentQuery(q, es)
+			rng.Shuffle(len(es), func(i, j int) { es[i], es[j] = es[j], es[i] })
+			got := applyDocumentQuery(q, es)
+			if len(got) != len(want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(want))
+			}
+			for idx := range got {
+				if got[idx].Key != want[idx].Key {
+					t.Fatalf("entry %d is %s, want %s", idx, got[idx].Key, want[idx].Key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+// query language:
+//   expr    := and ("OR" and)*
+//   and     := unary (["AND"] unary)*
+//   unary   := ("-" | "NOT") unary | primary
+//   primary := "(" expr ")" | field ":" value | value
+// fields: tag, type, title, author, cid, key, after, before
+// A bare value matches the title. Values with spaces can be quoted: title:"state budget".
+// Dates are written as 2006-01-02 (UTC).
+
+const queryDateLayout = "2006-01-02"
+
+var maxTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type SyntaxError struct {
+	// 1-based column in the expression
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenMinus
+	tokenWord
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	// tokenWord: field is "" for a bare value
+	field  string
+	value  string
+	quoted bool
+}
+
+func lexQuery(expr string) ([]token, error) {
+	rs := []rune(expr)
+	tokens := make([]token, 0)
+	idx := 0
+	readValue := func() (string, bool, error) {
+		if idx < len(rs) && rs[idx] == '"' {
+			start := idx
+			idx++
+			begin := idx
+			for idx < len(rs) && rs[idx] != '"' {
+				idx++
+			}
+			if idx >= len(rs) {
+				return "", true, &SyntaxError{start + 1, "unterminated quote"}
+			}
+			val := string(rs[begin:idx])
+			idx++
+			return val, true, nil
+		}
+		begin := idx
+		for idx < len(rs) && !unicode.IsSpace(rs[idx]) && rs[idx] != '(' && rs[idx] != ')' {
+			idx++
+		}
+		return string(rs[begin:idx]), false, nil
+	}
+
+	for idx < len(rs) {
+		r := rs[idx]
+		switch {
+		case unicode.IsSpace(r):
+			idx++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: idx})
+			idx++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: idx})
+			idx++
+		case r == '-':
+			tokens = append(tokens, token{kind: tokenMinus, pos: idx})
+			idx++
+		default:
+			tk := token{kind: tokenWord, pos: idx}
+			begin := idx
+			for idx < len(rs) && unicode.IsLetter(rs[idx]) {
+				idx++
+			}
+			if idx > begin && idx < len(rs) && rs[idx] == ':' {
+				tk.field = strings.ToLower(string(rs[begin:idx]))
+				idx++
+			} else {
+				idx = begin
+			}
+
+			val, quoted, err := readValue()
+			if err != nil {
+				return nil, err
+			}
+			tk.value, tk.quoted = val, quoted
+			tokens = append(tokens, tk)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEnd, pos: len(rs)})
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []token
+	idx    int
+}
+
+func (p *queryParser) peek() token { return p.tokens[p.idx] }
+func (p *queryParser) next() token {
+	tk := p.tokens[p.idx]
+	if tk.kind != tokenEnd {
+		p.idx++
+	}
+	return tk
+}
+func isKeyword(tk token, kw string) bool {
+	return tk.kind == tokenWord && tk.field == "" && !tk.quoted && tk.value == kw
+}
+
+func (p *queryParser) parseOr() (query.Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	fs := []query.Filter{f}
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+		f, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+	return OrFilter{fs}, nil
+}
+
+func (p *queryParser) parseAnd() (query.Filter, error) {
+	fs := make([]query.Filter, 0)
+	for {
+		tk := p.peek()
+		if tk.kind == tokenEnd || tk.kind == tokenRParen || isKeyword(tk, "OR") {
+			break
+		}
+		if isKeyword(tk, "AND") {
+			if len(fs) == 0 {
+				return nil, &SyntaxError{tk.pos + 1, "AND without left operand"}
+			}
+			p.next()
+		}
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 0 {
+		tk := p.peek()
+		return nil, &SyntaxError{tk.pos + 1, "expected a term"}
+	}
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+	return AndFilter{fs}, nil
+}
+
+func (p *queryParser) parseUnary() (query.Filter, error) {
+	tk := p.peek()
+	if tk.kind == tokenMinus || isKeyword(tk, "NOT") {
+		p.next()
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotFilter{f}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (query.Filter, error) {
+	tk := p.next()
+	switch tk.kind {
+	case tokenLParen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != tokenRParen {
+			return nil, &SyntaxError{end.pos + 1, "missing )"}
+		}
+		return f, nil
+	case tokenWord:
+		return termFilter(tk)
+	case tokenRParen:
+		return nil, &SyntaxError{tk.pos + 1, "unexpected )"}
+	default:
+		return nil, &SyntaxError{tk.pos + 1, "expected a term"}
+	}
+}
+
+func parseQueryDate(tk token) (time.Time, error) {
+	t, err := time.Parse(queryDateLayout, tk.value)
+	if err != nil {
+		return time.Time{}, &SyntaxError{tk.pos + 1, "invalid date " + tk.value + " (want " + queryDateLayout + ")"}
+	}
+	return t, nil
+}
+
+func keyFilter(key string) query.Filter {
+	if strings.Contains(key, "/") {
+		return crdt.KeyMatchFilter{Key: key}
+	}
+	return crdt.KeyExistFilter{Key: key}
+}
+
+func termFilter(tk token) (query.Filter, error) {
+	if tk.value == "" {
+		return nil, &SyntaxError{tk.pos + 1, "empty value for " + tk.field}
+	}
+
+	switch tk.field {
+	case "", "title":
+		return TitleFilter{tk.value}, nil
+	case "tag":
+		return TagsFilter{[]string{tk.value}}, nil
+	case "type":
+		return DocTypesFilter{[]string{tk.value}}, nil
+	case "author":
+		return AuthorFilter{tk.value}, nil
+	case "cid":
+		return CidsFilter{[]string{tk.value}}, nil
+	case "key":
+		return keyFilter(tk.value), nil
+	case "after":
+		t, err := parseQueryDate(tk)
+		if err != nil {
+			return nil, err
+		}
+		return TimeFilter{t, maxTime}, nil
+	case "before":
+		t, err := parseQueryDate(tk)
+		if err != nil {
+			return nil, err
+		}
+		return TimeFilter{time.Time{}, t}, nil
+	default:
+		return nil, &SyntaxError{tk.pos + 1, "unknown field " + tk.field}
+	}
+}
+
+// ParseQuery compiles a query expression into a filter tree, e.g.
+// tag:ministry type:pdf -tag:rumor (title:budget OR title:tax) after:2019-01-01 author:alice
+func ParseQuery(expr string) (query.Filter, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tk := p.peek(); tk.kind != tokenEnd {
+		return nil, &SyntaxError{tk.pos + 1, "unexpected )"}
+	}
+	return f, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+func TestParseQuery(t *testing.T) {
+	date := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want query.Filter
+	}{
+		{"budget", TitleFilter{"budget"}},
+		{`title:"state budget"`, TitleFilter{"state budget"}},
+		{"tag:ministry type:pdf", AndFilter{[]query.Filter{
+			TagsFilter{[]string{"ministry"}},
+			DocTypesFilter{[]string{"pdf"}},
+		}}},
+		{"tag:a AND tag:b", AndFilter{[]query.Filter{
+			TagsFilter{[]string{"a"}},
+			TagsFilter{[]string{"b"}},
+		}}},
+		{"tag:a OR tag:b tag:c", OrFilter{[]query.Filter{
+			TagsFilter{[]string{"a"}},
+			AndFilter{[]query.Filter{TagsFilter{[]string{"b"}}, TagsFilter{[]string{"c"}}}},
+		}}},
+		{"-tag:rumor", NotFilter{TagsFilter{[]string{"rumor"}}}},
+		{"NOT NOT tag:a", NotFilter{NotFilter{TagsFilter{[]string{"a"}}}}},
+		{"(title:budget OR title:tax) author:alice", AndFilter{[]query.Filter{
+			OrFilter{[]query.Filter{TitleFilter{"budget"}, TitleFilter{"tax"}}},
+			AuthorFilter{"alice"},
+		}}},
+		{"after:2019-01-01", TimeFilter{date, maxTime}},
+		{"before:2019-01-01", TimeFilter{time.Time{}, date}},
+		{"cid:bafy", CidsFilter{[]string{"bafy"}}},
+		{"key:pid/alice/doc", crdt.KeyMatchFilter{Key: "pid/alice/doc"}},
+		{"key:alice", crdt.KeyExistFilter{Key: "alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseQuery(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuerySyntaxError(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{"", 1},
+		{"AND tag:a", 1},
+		{"tag:a OR", 9},
+		{"(tag:a", 7},
+		{"tag:a )", 7},
+		{"tag:a ()", 8},
+		{`title:"budget`, 7},
+		{"after:2019-13-01", 1},
+		{"tag:a before:yesterday", 7},
+		{"color:red", 1},
+		{"tag:a tag:", 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseQuery(tt.expr)
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("got %v, want a syntax error", err)
+			}
+			if se.Column != tt.column {
+				t.Errorf("got column %d (%s), want %d", se.Column, se.Msg, tt.column)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+const ratingCategory = "rating"
+
+// rating key: pid/rating/docpid/docusername/docname/n
+// n counts the ratings of pid for the document from 1, without gaps.
+// Ratings put before the numbering have a random id instead.
+func ratingPrefix(docKey string) string {
+	return ratingCategory + "/" + strings.TrimPrefix(docKey, "/")
+}
+
+// ratingSeq returns n of the rating key, or 0 for a random id.
+func ratingSeq(key string) int64 {
+	keys := splitKey(key)
+	n, err := strconv.ParseInt(keys[len(keys)-1], 10, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+type Verdict int32
+
+const (
+	Authentic Verdict = iota
+	Doubtful
+	Fabricated
+)
+
+var Verdicts = []Verdict{Authentic, Doubtful, Fabricated}
+
+func (v Verdict) String() string {
+	switch v {
+	case Authentic:
+		return "authentic"
+	case Doubtful:
+		return "doubtful"
+	case Fabricated:
+		return "fabricated"
+	default:
+		return "unknown"
+	}
+}
+func (v Verdict) score() float64 {
+	switch v {
+	case Authentic:
+		return 1
+	case Fabricated:
+		return -1
+	default:
+		return 0
+	}
+}
+
+const (
+	MinConfidence = 1
+	MaxConfidence = 3
+)
+
+type Rating struct {
+	Doc        string
+	Verdict    Verdict
+	Confidence int
+	Author     string
+	Time       time.Time
+}
+
+func NewRating(docKey string, verdict Verdict, confidence int, t time.Time) *Rating {
+	docKey = strings.TrimPrefix(docKey, "/")
+	return &Rating{docKey, verdict, confidence, "", t}
+}
+func (r *Rating) Marshal() []byte {
+	mt, _ := r.Time.MarshalBinary()
+	mr := &pb.Rating{
+		Doc:        r.Doc,
+		Verdict:    int32(r.Verdict),
+		Confidence: int32(r.Confidence),
+		Name:       r.Author,
+		Time:       mt,
+	}
+	m, _ := proto.Marshal(mr)
+	return m
+}
+func (r *Rating) Unmarshal(m []byte) error {
+	mr := &pb.Rating{}
+	if err := proto.Unmarshal(m, mr); err != nil {
+		return err
+	}
+	t := time.Time{}
+	if err := t.UnmarshalBinary(mr.GetTime()); err != nil {
+		return err
+	}
+
+	r.Doc = mr.GetDoc()
+	r.Verdict = Verdict(mr.GetVerdict())
+	r.Confidence = int(mr.GetConfidence())
+	r.Author = mr.GetName()
+	r.Time = t
+	return nil
+}
+func (r *Rating) isValid() bool {
+	validVerdict := r.Verdict >= Authentic && r.Verdict <= Fabricated
+	validConf := r.Confidence >= MinConfidence && r.Confidence <= MaxConfidence
+	return validVerdict && validConf
+}
+
+type NamedRating struct {
+	*Rating
+	Name string
+}
+
+// Signer returns the pid of the verify key which signed the rating.
+func (nr *NamedRating) Signer() string {
+	return splitKey(nr.Name)[0]
+}
+
+type RatingSummary struct {
+	Authentic  int
+	Doubtful   int
+	Fabricated int
+	// confidence weighted mean of the verdicts in [-1, 1]
+	// (authentic: 1, doubtful: 0, fabricated: -1)
+	Score float64
+}
+
+func NewRatingSummary(nrs []*NamedRating) *RatingSummary {
+	rs := &RatingSummary{}
+	weighted, weights := 0.0, 0
+	for _, nr := range nrs {
+		switch nr.Verdict {
+		case Authentic:
+			rs.Authentic++
+		case Doubtful:
+			rs.Doubtful++
+		case Fabricated:
+			rs.Fabricated++
+		}
+		weighted += nr.Verdict.score() * float64(nr.Confidence)
+		weights += nr.Confidence
+	}
+	if weights > 0 {
+		rs.Score = weighted / float64(weights)
+	}
+	return rs
+}
+func (rs *RatingSummary) Total() int {
+	return rs.Authentic + rs.Doubtful + rs.Fabricated
+}
+
+// PutRating replaces the current rating of the user identity for the document.
+func (ds *documentStore) PutRating(r *Rating) error {
+	return ds.PutRatingAs(ds.identity(), r)
+}
+
+// PutRatingAs puts the rating signed by ui.
+// Anonymous identities cannot rate, since a fresh key pair would count as another rater.
+func (ds *documentStore) PutRatingAs(ui *UserIdentity, r *Rating) error {
+	ui = parseUserIdentity(ui)
+	if ui.userName == anonymousName {
+		return errors.New("anonymous identities cannot rate")
+	}
+	if len(splitKey(r.Doc)) != 3 {
+		return errors.New("invalid document key")
+	}
+	if !r.isValid() {
+		return errors.New("invalid rating")
+	}
+
+	r.Author = ui.userName
+	prefix := ratingPrefix(r.Doc)
+	unlock := ds.keyLocks.lock(pid(ui) + "/" + prefix)
+	defer unlock()
+	n := int64(1)
+	for {
+		if _, err := ds.ss.Get(pid(ui) + "/" + prefix + "/" + strconv.FormatInt(n, 10)); err != nil {
+			break
+		}
+		n++
+	}
+	return ds.put(ui, prefix+"/"+strconv.FormatInt(n, 10), r.Marshal())
+}
+
+// QueryRatings returns the current (latest) rating of each verify key.
+func (ds *documentStore) QueryRatings(docKey string) ([]*NamedRating, error) {
+	docKey = strings.TrimPrefix(docKey, "/")
+	q := query.Query{
+		Filters: []query.Filter{
+			crdt.KeyMatchFilter{Key: "*/" + ratingPrefix(docKey)},
+			ratingFilter{},
+		},
+	}
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return nil, err
+	}
+	rs = query.NaiveQueryApply(q, rs)
+	return latestRatings(rs)[docKey], nil
+}
+
+// latestRatings returns the latest rating of each verify key, by document.
+// Ratings are ordered by their number, and ratings with random ids by time, before any numbered one.
+// Anonymous ratings are left out.
+func latestRatings(rs query.Results) map[string][]*NamedRating {
+	latest := make(map[string]map[string]*NamedRating)
+	signers := make(map[string][]string)
+	for res := range rs.Next() {
+		r := &Rating{}
+		if err := r.Unmarshal(res.Value); err != nil || r.Author == anonymousName {
+			continue
+		}
+		nr := &NamedRating{r, res.Key}
+		signer := nr.Signer()
+		if _, ok := latest[r.Doc]; !ok {
+			latest[r.Doc] = make(map[string]*NamedRating)
+		}
+		cur, ok := latest[r.Doc][signer]
+		if !ok {
+			signers[r.Doc] = append(signers[r.Doc], signer)
+			latest[r.Doc][signer] = nr
+		} else if ratingAfter(nr, cur) {
+			latest[r.Doc][signer] = nr
+		}
+	}
+
+	nrss := make(map[string][]*NamedRating, len(signers))
+	for doc, docSigners := range signers {
+		nrs := make([]*NamedRating, len(docSigners))
+		for idx, signer := range docSigners {
+			nrs[idx] = latest[doc][signer]
+		}
+		nrss[doc] = nrs
+	}
+	return nrss
+}
+
+func ratingAfter(nr, cur *NamedRating) bool {
+	n, curN := ratingSeq(nr.Name), ratingSeq(cur.Name)
+	if n != curN {
+		return n > curN
+	}
+	return nr.Time.After(cur.Time)
+}
+
+func (ds *documentStore) RatingSummary(docKey string) (*RatingSummary, error) {
+	nrs, err := ds.QueryRatings(docKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewRatingSummary(nrs), nil
+}
+
+// RatingSummaries returns the summary of each document with one scan of the store.
+func (ds *documentStore) RatingSummaries(docKeys []string) (map[string]*RatingSummary, error) {
+	wanted := make(map[string]struct{}, len(docKeys))
+	for _, key := range docKeys {
+		wanted[strings.TrimPrefix(key, "/")] = struct{}{}
+	}
+	q := query.Query{
+		Filters: []query.Filter{
+			ratingDocsFilter{wanted},
+			ratingFilter{},
+		},
+	}
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return nil, err
+	}
+	nrss := latestRatings(query.NaiveQueryApply(q, rs))
+
+	summaries := make(map[string]*RatingSummary, len(wanted))
+	for key := range wanted {
+		summaries[key] = NewRatingSummary(nrss[key])
+	}
+	return summaries, nil
+}
+
+type ratingFilter struct{}
+
+func (f ratingFilter) Filter(e query.Entry) bool {
+	// e.Key: pid/rating/docpid/docusername/docname/id
+	keys := splitKey(e.Key)
+	if len(keys) != 6 || keys[1] != ratingCategory {
+		return false
+	}
+
+	r := &Rating{}
+	if err := r.Unmarshal(e.Value); err != nil {
+		return false
+	}
+	return r.isValid() && r.Doc == strings.Join(keys[2:5], "/")
+}
+
+// ratingDocsFilter passes ratings of the docs.
+type ratingDocsFilter struct {
+	docs map[string]struct{}
+}
+
+func (f ratingDocsFilter) Filter(e query.Entry) bool {
+	// e.Key: pid/rating/docpid/docusername/docname/id
+	keys := splitKey(e.Key)
+	if len(keys) != 6 {
+		return false
+	}
+	_, ok := f.docs[strings.Join(keys[2:5], "/")]
+	return ok
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+func TestLatestRatings(t *testing.T) {
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	rating := func(verdict Verdict, author string, t time.Time) []byte {
+		return (&Rating{"dpid/bob/doc", verdict, 1, author, t}).Marshal()
+	}
+	prefix := "/pid/rating/dpid/bob/doc/"
+	es := []query.Entry{
+		// the numbered rating is the latest, whatever its time says
+		{Key: prefix + "2", Value: rating(Authentic, "alice", base)},
+		{Key: prefix + "1", Value: rating(Doubtful, "alice", base.Add(time.Hour))},
+		{Key: prefix + "AAAAAAAAAAA=", Value: rating(Fabricated, "alice", base.Add(2*time.Hour))},
+		// random ids only, ordered by time
+		{Key: "/carol/rating/dpid/bob/doc/AAAAAAAAAAA=", Value: rating(Fabricated, "carol", base.Add(time.Hour))},
+		{Key: "/carol/rating/dpid/bob/doc/BBBBBBBBBBB=", Value: rating(Doubtful, "carol", base)},
+		{Key: "/anon/rating/dpid/bob/doc/1", Value: rating(Authentic, anonymousName, base)},
+	}
+
+	nrs := latestRatings(query.ResultsWithEntries(query.Query{}, es))["dpid/bob/doc"]
+	want := map[string]Verdict{"pid": Authentic, "carol": Fabricated}
+	if len(nrs) != len(want) {
+		t.Fatalf("%d ratings, want %d", len(nrs), len(want))
+	}
+	for _, nr := range nrs {
+		if v, ok := want[nr.Signer()]; !ok || nr.Verdict != v {
+			t.Errorf("%s rated %s, want %s", nr.Signer(), nr.Verdict, v)
+		}
+	}
+}
+
+func TestPutRatingAs(t *testing.T) {
+	ds := newTestDocumentStore(t, newTestBootstrap(t), "rating")
+	ui := newTestIdentity("alice")
+	key := putTestDocument(t, ds, ui, "doc", "title", "text")
+
+	now := time.Now()
+	if err := ds.PutRatingAs(nil, NewRating(key, Authentic, 1, now)); err == nil {
+		t.Error("put an anonymous rating")
+	}
+	if err := ds.PutRatingAs(ui, NewRating(key, Doubtful, 1, now)); err != nil {
+		t.Fatal(err)
+	}
+	// a clock behind does not keep the older rating
+	if err := ds.PutRatingAs(ui, NewRating(key, Fabricated, 1, now.Add(-time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+
+	nrs, err := ds.QueryRatings(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nrs) != 1 || nrs[0].Verdict != Fabricated {
+		t.Fatalf("ratings %v, want the second one", nrs)
+	}
+	if ratingSeq(nrs[0].Name) != 2 {
+		t.Errorf("rating key %s, want the second number", nrs[0].Name)
+	}
+}
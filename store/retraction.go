@@ -0,0 +1,151 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+const retractionCategory = "retraction"
+
+// retraction key: pid/retraction/username/docname
+func retractionKey(docKey string) string {
+	keys := splitKey(docKey)
+	return strings.Join([]string{keys[0], retractionCategory, keys[1], keys[2]}, "/")
+}
+func retractionDocKey(key string) (string, bool) {
+	keys := splitKey(key)
+	if len(keys) != 4 || keys[1] != retractionCategory {
+		return "", false
+	}
+	return strings.Join([]string{keys[0], keys[2], keys[3]}, "/"), true
+}
+
+type Retraction struct {
+	Reason string
+	Time   time.Time
+}
+
+func (r *Retraction) Marshal() []byte {
+	mt, _ := r.Time.MarshalBinary()
+	mr := &pb.Retraction{
+		Reason: r.Reason,
+		Time:   mt,
+	}
+	m, _ := proto.Marshal(mr)
+	return m
+}
+func (r *Retraction) Unmarshal(m []byte) error {
+	mr := &pb.Retraction{}
+	if err := proto.Unmarshal(m, mr); err != nil {
+		return err
+	}
+	t := time.Time{}
+	if err := t.UnmarshalBinary(mr.GetTime()); err != nil {
+		return err
+	}
+
+	r.Reason = mr.GetReason()
+	r.Time = t
+	return nil
+}
+
+// IncludeRetracted makes Query return retracted documents as well.
+// Retracted documents are marked by NamedDocument.Retraction.
+type IncludeRetracted struct{}
+
+func (f IncludeRetracted) Filter(e query.Entry) bool { return true }
+
+func withoutIncludeRetracted(fs []query.Filter) ([]query.Filter, bool) {
+	include := false
+	others := make([]query.Filter, 0, len(fs))
+	for _, f := range fs {
+		if _, ok := f.(IncludeRetracted); ok {
+			include = true
+		} else {
+			others = append(others, f)
+		}
+	}
+	return others, include
+}
+
+// Retract withdraws the document permanently.
+// Only the author (the identity which signed the document) can retract it.
+func (ds *documentStore) Retract(key, reason string) error {
+	return ds.RetractAs(ds.identity(), key, reason)
+}
+func (ds *documentStore) RetractAs(ui *UserIdentity, key, reason string) error {
+	ui = parseUserIdentity(ui)
+	keys := splitKey(key)
+	if len(keys) != 3 {
+		return errors.New("invalid document key")
+	}
+	if keys[0] != pid(ui) || keys[1] != ui.userName {
+		return errors.New("only the author can retract the document")
+	}
+	if reason == "" {
+		return errors.New("empty reason")
+	}
+	if _, _, err := ds.latestRevision(key); err != nil {
+		return err
+	}
+
+	r := &Retraction{reason, time.Now().UTC()}
+	rKeys := splitKey(retractionKey(key))
+	return ds.put(ui, strings.Join(rKeys[1:], "/"), r.Marshal())
+}
+
+func (ds *documentStore) GetRetraction(key string) (*Retraction, error) {
+	if len(splitKey(key)) != 3 {
+		return nil, errors.New("invalid document key")
+	}
+	m, err := ds.ss.Get(retractionKey(key))
+	if err != nil {
+		return nil, err
+	}
+	r := &Retraction{}
+	if err := r.Unmarshal(m); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// retraction returns nil if the document is not retracted.
+func (ds *documentStore) retraction(key string) *Retraction {
+	r, err := ds.GetRetraction(key)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+func retractionsFromEntries(es []query.Entry) map[string]*Retraction {
+	rs := make(map[string]*Retraction)
+	for _, e := range es {
+		docKey, ok := retractionDocKey(e.Key)
+		if !ok {
+			continue
+		}
+		r := &Retraction{}
+		if err := r.Unmarshal(e.Value); err != nil {
+			continue
+		}
+		rs[docKey] = r
+	}
+	return rs
+}
+
+func withoutRetracted(es []query.Entry, retractions map[string]*Retraction) []query.Entry {
+	others := make([]query.Entry, 0, len(es))
+	for _, e := range es {
+		if _, ok := retractions[strings.TrimPrefix(e.Key, "/")]; !ok {
+			others = append(others, e)
+		}
+	}
+	return others
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+const revisionCategory = "revision"
+
+// the first revision key: pid/username/docname
+// the n-th revision key: pid/revision/username/docname/n
+func revisionKey(docKey string, n int64) string {
+	keys := splitKey(docKey)
+	if n == 0 {
+		return strings.Join(keys, "/")
+	}
+	return strings.Join([]string{keys[0], revisionCategory, keys[1], keys[2], strconv.FormatInt(n, 10)}, "/")
+}
+
+// revisionDocKey returns the document key and the revision number of a revision key.
+func revisionDocKey(key string) (string, int64, bool) {
+	keys := splitKey(key)
+	if len(keys) != 5 || keys[1] != revisionCategory {
+		return "", -1, false
+	}
+	n, err := strconv.ParseInt(keys[4], 10, 64)
+	if err != nil || n <= 0 {
+		return "", -1, false
+	}
+	return strings.Join([]string{keys[0], keys[2], keys[3]}, "/"), n, true
+}
+
+func (ds *documentStore) getRevision(docKey string, n int64) (*Document, []byte, error) {
+	m, err := ds.ss.Get(revisionKey(docKey, n))
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := newEmptyDocument()
+	if err := doc.Unmarshal(m); err != nil {
+		return nil, nil, err
+	}
+	if doc.Revision != n {
+		return nil, nil, errors.New("invalid revision")
+	}
+	return doc, m, nil
+}
+
+// revisions are numbered without gaps, so the latest one is found by probing.
+func (ds *documentStore) latestRevision(docKey string) (*Document, []byte, error) {
+	return ds.latestRevisionFrom(docKey, 0)
+}
+
+// latestRevisionFrom probes from the n-th revision, which must exist.
+func (ds *documentStore) latestRevisionFrom(docKey string, n int64) (*Document, []byte, error) {
+	doc, m, err := ds.getRevision(docKey, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		next, nextM, err := ds.getRevision(docKey, doc.Revision+1)
+		if err != nil {
+			return doc, m, nil
+		}
+		doc, m = next, nextM
+	}
+}
+
+// putRevision records doc as the next revision of username/docname.
+// The previous revision is added to ipfs and linked by its cid.
+// The number is allocated by probing, so puts of the same document are serialized.
+func (ds *documentStore) putRevision(ui *UserIdentity, name string, doc *Document) error {
+	docKey := pid(ui) + "/" + name
+	unlock := ds.keyLocks.lock(docKey)
+	defer unlock()
+	prev, mPrev, err := ds.latestRevision(docKey)
+	if err != nil {
+		doc.Prev = ""
+		doc.Revision = 0
+		return ds.put(ui, name, doc.Marshal())
+	}
+
+	prevCid, err := ds.Ipfs().Add(mPrev)
+	if err != nil {
+		return err
+	}
+	doc.Prev = prevCid
+	doc.Revision = prev.Revision + 1
+
+	keys := splitKey(revisionKey(docKey, doc.Revision))
+	return ds.put(ui, strings.Join(keys[1:], "/"), doc.Marshal())
+}
+
+func (ds *documentStore) GetRevision(key string, n int64) (*NamedDocument, error) {
+	if len(splitKey(key)) != 3 {
+		return nil, errors.New("invalid document key")
+	}
+	doc, _, err := ds.getRevision(key, n)
+	if err != nil {
+		return nil, err
+	}
+	return &NamedDocument{doc, key, ds.retraction(key), ds.signer(revisionKey(key, n))}, nil
+}
+
+// Revisions returns all revisions of the document from newer to older.
+func (ds *documentStore) Revisions(key string) ([]*NamedDocument, error) {
+	if len(splitKey(key)) != 3 {
+		return nil, errors.New("invalid document key")
+	}
+	latest, _, err := ds.latestRevision(key)
+	if err != nil {
+		return nil, err
+	}
+
+	r := ds.retraction(key)
+	ndocs := []*NamedDocument{{latest, key, r, ds.signer(revisionKey(key, latest.Revision))}}
+	for n := latest.Revision - 1; n >= 0; n-- {
+		doc, _, err := ds.getRevision(key, n)
+		if err != nil {
+			return nil, err
+		}
+		ndocs = append(ndocs, &NamedDocument{doc, key, r, ds.signer(revisionKey(key, n))})
+	}
+	return ndocs, nil
+}
+
+// latestRevisionEntries replaces the value of each document entry with its latest revision.
+// Revision entries themselves are dropped.
+func latestRevisionEntries(entries []query.Entry) []query.Entry {
+	es := make([]query.Entry, 0, len(entries))
+	revs := make(map[string]query.Entry)
+	revNums := make(map[string]int64)
+	for _, e := range entries {
+		docKey, n, ok := revisionDocKey(e.Key)
+		if !ok {
+			es = append(es, e)
+			continue
+		}
+
+		doc := newEmptyDocument()
+		if err := doc.Unmarshal(e.Value); err != nil || doc.Revision != n {
+			continue
+		}
+		if cur, ok := revNums[docKey]; !ok || n > cur {
+			revNums[docKey] = n
+			revs[docKey] = e
+		}
+	}
+
+	for idx, e := range es {
+		if rev, ok := revs[strings.TrimPrefix(e.Key, "/")]; ok {
+			es[idx].Value = rev.Value
+		}
+	}
+	return es
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentRevisions(t *testing.T) {
+	ds := newTestDocumentStore(t, newTestBootstrap(t), "revision")
+	ui := newTestIdentity("alice")
+	key := putTestDocument(t, ds, ui, "doc", "first", "first text")
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 4; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			info := NewDocumentInfo("title "+strconv.Itoa(idx), "description", nil, nil, time.Now())
+			if err := ds.PutAs(ui, "doc", info, NewTypedData("text", strings.NewReader("text"))); err != nil {
+				t.Error(err)
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	revs, err := ds.Revisions(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 5 {
+		t.Fatalf("%d revisions, want 5", len(revs))
+	}
+	titles := make(map[string]struct{})
+	for _, rev := range revs {
+		titles[rev.Title] = struct{}{}
+	}
+	if len(titles) != 5 {
+		t.Errorf("%d distinct revisions, want 5: a put overwrote another", len(titles))
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"errors"
+	"strings"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+	crdtpb "github.com/pilinsin/p2p-verse/crdt/pb"
+)
+
+// SignedEntry is an entry of the signature store with the signature of its author.
+// Key starts with the pid of the author.
+type SignedEntry struct {
+	Key   string
+	Value []byte
+	Sign  []byte
+}
+
+func (se *SignedEntry) Verify() bool {
+	keys := splitKey(se.Key)
+	if len(keys) < 2 {
+		return false
+	}
+	vk, err := crdt.StrToPubKey(keys[0])
+	if err != nil {
+		return false
+	}
+	ok, err := vk.Verify(se.Value, se.Sign)
+	return err == nil && ok
+}
+
+// rawSignatureStore is crdt.IRawSignatureStore of p2p-verse.
+// crdt.ISignatureStore strips the signatures from the values it returns,
+// so the raw entries are read and put through this one.
+type rawSignatureStore interface {
+	GetRaw(string) ([]byte, error)
+	QueryRaw(...query.Query) (query.Results, error)
+	PutPresigned(key string, val, sign []byte) error
+}
+
+func rawStore(ss crdt.ISignatureStore) (rawSignatureStore, error) {
+	if rs, ok := ss.(rawSignatureStore); ok {
+		return rs, nil
+	}
+	return newEmbeddedRawStore(ss)
+}
+
+// signedEntries returns all entries of the signature store with their signatures.
+func (ds *documentStore) signedEntries() ([]*SignedEntry, error) {
+	rq, err := rawStore(ds.ss)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := rq.QueryRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	ses := make([]*SignedEntry, 0)
+	for res := range rs.Next() {
+		// the store puts its name unsigned when it is made
+		if res.Error != nil || len(splitKey(res.Key)) < 2 {
+			continue
+		}
+		sd := &crdtpb.SignatureData{}
+		if err := proto.Unmarshal(res.Value, sd); err != nil {
+			continue
+		}
+		key := strings.TrimPrefix(res.Key, "/")
+		ses = append(ses, &SignedEntry{key, sd.GetValue(), sd.GetSign()})
+	}
+	return ses, nil
+}
+
+// signedEntry returns the entry at key with its signature.
+func (ds *documentStore) signedEntry(key string) (*SignedEntry, error) {
+	rq, err := rawStore(ds.ss)
+	if err != nil {
+		return nil, err
+	}
+	m, err := rq.GetRaw(key)
+	if err != nil {
+		return nil, err
+	}
+	sd := &crdtpb.SignatureData{}
+	if err := proto.Unmarshal(m, sd); err != nil {
+		return nil, err
+	}
+	return &SignedEntry{key, sd.GetValue(), sd.GetSign()}, nil
+}
+
+// Signer is the author of an entry, told by the pid in its key.
+type Signer struct {
+	// raw verify key
+	Verify      []byte
+	Fingerprint string
+	// Valid is true if the signature of the entry is checked with the verify key.
+	Valid bool
+}
+
+// VerifyKeyOf returns the raw verify key of the author of the entry at key.
+func VerifyKeyOf(key string) ([]byte, error) {
+	keys := splitKey(key)
+	if len(keys) < 2 {
+		return nil, errors.New("invalid key")
+	}
+	vk, err := crdt.StrToPubKey(keys[0])
+	if err != nil {
+		return nil, err
+	}
+	return vk.Raw()
+}
+
+// signer returns the signer of the entry at key. The signature is checked again,
+// though invalid entries are rejected by the store when they are put.
+func (ds *documentStore) signer(key string) *Signer {
+	mv, err := VerifyKeyOf(key)
+	if err != nil {
+		return nil
+	}
+	sgn := &Signer{Verify: mv, Fingerprint: fingerprint(mv)}
+	if se, err := ds.signedEntry(key); err == nil {
+		sgn.Valid = se.Verify()
+	}
+	return sgn
+}
+
+// putSigned stores an entry signed by someone else.
+// Existing keys are kept as they are, like any other put.
+func (ds *documentStore) putSigned(se *SignedEntry) error {
+	if !se.Verify() {
+		return errors.New("invalid signature")
+	}
+	rs, err := rawStore(ds.ss)
+	if err != nil {
+		return err
+	}
+	return rs.PutPresigned(se.Key, se.Value, se.Sign)
+}
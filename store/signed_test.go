@@ -0,0 +1,83 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	pv "github.com/pilinsin/p2p-verse"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+func newTestBootstrap(t *testing.T) pv.IBootstrap {
+	b, err := pv.NewBootstrap(pv.SampleHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+func newTestSignatureStore(t *testing.T, b pv.IBootstrap, name string, vk IVerfKey, sk ISignKey) crdt.ISignatureStore {
+	v := crdt.NewVerse(pv.SampleHost, filepath.Join(t.TempDir(), name), false, b.AddrInfo())
+	opts := &crdt.StoreOpts{Pub: vk, Priv: sk}
+	st, err := v.NewStore(name, "signature", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(st.Close)
+	return st.(crdt.ISignatureStore)
+}
+
+func TestSignedEntries(t *testing.T) {
+	b := newTestBootstrap(t)
+	kp := NewKeyPair()
+	ds := &documentStore{ss: newTestSignatureStore(t, b, "signed", kp.Verify(), kp.Sign())}
+	if err := ds.ss.Put("alice/doc", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	key := crdt.PubKeyToStr(kp.Verify()) + "/alice/doc"
+
+	ses, err := ds.signedEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, se := range ses {
+		if se.Key == key {
+			found = true
+			if !se.Verify() {
+				t.Error("listed entry is not verified")
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("%s is not listed", key)
+	}
+
+	se, err := ds.signedEntry(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !se.Verify() || string(se.Value) != "value" {
+		t.Errorf("got %q (verified %v), want verified value", se.Value, se.Verify())
+	}
+	if sgn := ds.signer(key); sgn == nil || !sgn.Valid {
+		t.Errorf("signer %v, want a valid one", sgn)
+	}
+
+	okp := NewKeyPair()
+	other := &documentStore{ss: newTestSignatureStore(t, b, "other", okp.Verify(), okp.Sign())}
+	if err := other.putSigned(se); err != nil {
+		t.Fatal(err)
+	}
+	got, err := other.signedEntry(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Verify() || string(got.Value) != "value" {
+		t.Errorf("got %q (verified %v), want verified value", got.Value, got.Verify())
+	}
+	if err := other.putSigned(&SignedEntry{key, []byte("forged"), se.Sign}); err == nil {
+		t.Error("put a forged entry")
+	}
+}
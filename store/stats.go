@@ -0,0 +1,120 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+)
+
+// months are written as 2006-01 (UTC)
+const monthLayout = timeBucketLayout
+
+type StoreStats struct {
+	Documents int
+	Tags      map[string]int
+	DocTypes  map[string]int
+	// author: pid/username
+	Authors map[string]int
+	Months  map[string]int
+	// total size of the data referenced by cids, as declared by the uploaders. Each cid is counted once.
+	// It is not verified against the data, which may not be local.
+	DeclaredSize int64
+	// cids whose size was not declared, e.g. the ones of documents of older versions
+	UnknownSizeCids int
+}
+
+func newStoreStats() *StoreStats {
+	return &StoreStats{
+		Tags:     make(map[string]int),
+		DocTypes: make(map[string]int),
+		Authors:  make(map[string]int),
+		Months:   make(map[string]int),
+	}
+}
+
+func NewStoreStats(ndocs []*NamedDocument) *StoreStats {
+	s := newStoreStats()
+	cids := make(map[string]int64)
+	for _, ndoc := range ndocs {
+		s.Documents++
+		for _, tag := range ndoc.Tags {
+			s.Tags[tag]++
+		}
+		for _, docType := range ndoc.DocTypes {
+			s.DocTypes[docType]++
+		}
+		keys := splitKey(ndoc.Name)
+		if len(keys) == 3 {
+			s.Authors[keys[0]+"/"+keys[1]]++
+		}
+		s.Months[ndoc.Time.UTC().Format(monthLayout)]++
+		for _, tc := range ndoc.Cids {
+			if size, ok := cids[tc.Cid]; !ok || size == 0 {
+				cids[tc.Cid] = tc.Size
+			}
+		}
+	}
+
+	for _, size := range cids {
+		if size > 0 {
+			s.DeclaredSize += size
+		} else {
+			s.UnknownSizeCids++
+		}
+	}
+	return s
+}
+
+// Stats counts the documents matching the query (all documents if no query is given).
+func (ds *documentStore) Stats(qs ...query.Query) (*StoreStats, error) {
+	ndocs, err := ds.queryDocuments(qs...)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreStats(ndocs), nil
+}
+
+// MonthFilter matches the documents of the month (2006-01).
+func MonthFilter(month string) (TimeFilter, error) {
+	begin, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return TimeFilter{}, err
+	}
+	return TimeFilter{begin, begin.AddDate(0, 1, 0)}, nil
+}
+
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// TopCounts returns the n most frequent values (all values if n <= 0).
+func TopCounts(counts map[string]int, n int) []FacetCount {
+	fcs := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		fcs = append(fcs, FacetCount{value, count})
+	}
+	sort.Slice(fcs, func(i, j int) bool {
+		if fcs[i].Count == fcs[j].Count {
+			return fcs[i].Value < fcs[j].Value
+		}
+		return fcs[i].Count > fcs[j].Count
+	})
+	if n > 0 && n < len(fcs) {
+		fcs = fcs[:n]
+	}
+	return fcs
+}
+
+// SortedMonths returns the month counts from older to newer.
+func SortedMonths(months map[string]int) []FacetCount {
+	fcs := make([]FacetCount, 0, len(months))
+	for month, count := range months {
+		fcs = append(fcs, FacetCount{month, count})
+	}
+	sort.Slice(fcs, func(i, j int) bool {
+		return fcs[i].Value < fcs[j].Value
+	})
+	return fcs
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	query "github.com/ipfs/go-datastore/query"
@@ -28,14 +29,61 @@ func NewTypedData(tp string, data io.Reader) *TypedData {
 func (td *TypedData) Type() string    { return td.tp }
 func (td *TypedData) Data() io.Reader { return td.data }
 
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
 type IDocumentStore interface {
 	Close()
 	Ipfs() ipfs.Ipfs
 	SetUserIdentity(*UserIdentity)
 	Address() string
 	Put(string, *DocumentInfo, ...*TypedData) error
+	PutAs(*UserIdentity, string, *DocumentInfo, ...*TypedData) error
 	Get(string) (*NamedDocument, error)
+	GetRevision(string, int64) (*NamedDocument, error)
+	Revisions(string) ([]*NamedDocument, error)
+	Retract(string, string) error
+	RetractAs(*UserIdentity, string, string) error
+	GetRetraction(string) (*Retraction, error)
 	Query(...query.Query) (<-chan *NamedDocument, error) //time, tag, etc...
+	QueryPage(string, int, ...query.Query) (*Page, error)
+	Stats(...query.Query) (*StoreStats, error)
+	CidType(string) (string, error)
+	ExportArchive(io.Writer) (*ArchiveReport, error)
+	ImportArchive(io.ReaderAt, int64) (*ArchiveReport, error)
+	ExportManifest(io.Writer) error
+	ExportDelta(io.Writer, io.ReaderAt, int64) (*ArchiveReport, error)
+	Pin(string) error
+	Unpin(string) error
+	IsPinned(string) bool
+	Pins() ([]*PinInfo, error)
+	Quota() int64
+	SetQuota(int64) error
+	DiskUsage() (int64, error)
+	EnforceQuota() (int64, error)
+	GC(bool) (*GCReport, error)
+	Verify() (*VerifyReport, error)
+	StartMirror() error
+	StopMirror() error
+	Mirroring() bool
+	MirrorProgress() *MirrorProgress
+	Relevance(string) RelevanceOrder
+	PutComment(*Comment) error
+	PutCommentAs(*UserIdentity, *Comment) error
+	QueryComments(string) (<-chan *NamedComment, error)
+	PutRating(*Rating) error
+	PutRatingAs(*UserIdentity, *Rating) error
+	QueryRatings(string) ([]*NamedRating, error)
+	RatingSummary(string) (*RatingSummary, error)
+	RatingSummaries([]string) (map[string]*RatingSummary, error)
 }
 
 type documentStore struct {
@@ -43,12 +91,50 @@ type documentStore struct {
 	closer    func()
 	dirCloser func()
 	addr      string
-	userName  string
+	ui        *UserIdentity
 	is        ipfs.Ipfs
 	ss        crdt.ISignatureStore
+	watcher   *storeWatcher
+	textIndex *textIndex
+	docIndex  *docIndex
+	cidIndex  *cidIndex
+	keyMutex  *sync.Mutex
+	keyLocks  *keyLocks
+	pins      *pinManager
+	mirror    *mirror
+}
+
+// indexDir == "" keeps local indexes in memory only.
+func newDocumentStore(addr, indexDir string, dirCloser func(), ui *UserIdentity, is ipfs.Ipfs, ss crdt.ISignatureStore) *documentStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := newStoreWatcher(ss)
+
+	textPath, docPath, pinPath := "", "", ""
+	if indexDir != "" {
+		textPath = filepath.Join(indexDir, "fulltext")
+		docPath = filepath.Join(indexDir, "documents")
+		pinPath = filepath.Join(indexDir, "pins")
+	}
+	ti := newTextIndex(textPath, is)
+	watcher.subscribe(ti)
+	di := newDocIndex(docPath)
+	watcher.subscribe(di)
+	ci := newCidIndex()
+	watcher.subscribe(ci)
+
+	pm := newPinManager(pinPath)
+	mr := newMirror()
+	watcher.subscribe(mr)
+
+	ds := &documentStore{ctx, cancel, dirCloser, addr, ui, is, ss, watcher, ti, di, ci, &sync.Mutex{}, newKeyLocks(), pm, mr}
+	watcher.run(ctx)
+	ds.runQuota()
+	ds.runMirror()
+	return ds
 }
 
 func NewDocumentStore(title, bAddr, baseDir string) (IDocumentStore, error) {
+	ui := parseUserIdentity(nil)
 	bootstraps := pv.AddrInfosFromString(bAddr)
 	save := false
 	dirCloser := func() { os.Remove(baseDir) }
@@ -61,16 +147,16 @@ func NewDocumentStore(title, bAddr, baseDir string) (IDocumentStore, error) {
 
 	storeDir := filepath.Join(baseDir, "store")
 	v := crdt.NewVerse(i2p.NewI2pHost, storeDir, save, bootstraps...)
-	st, err := v.NewStore(pv.RandString(8), "signature")
+	opt := &crdt.StoreOpts{Pub: ui.verfKey, Priv: ui.signKey}
+	st, err := v.NewStore(pv.RandString(8), "signature", opt)
 	if err != nil {
 		is.Close()
 		return nil, err
 	}
 	ss := st.(crdt.ISignatureStore)
-	ctx, cancel := context.WithCancel(context.Background())
 
 	addr := bAddr + "/" + title + "/" + ss.Address()
-	return &documentStore{ctx, cancel, dirCloser, addr, "Anonymous", is, ss}, nil
+	return newDocumentStore(addr, "", dirCloser, ui, is, ss), nil
 }
 func LoadDocumentStore(addr, baseDir string) (IDocumentStore, error) {
 	ui := parseUserIdentity(nil)
@@ -96,9 +182,9 @@ func LoadDocumentStore(addr, baseDir string) (IDocumentStore, error) {
 		return nil, err
 	}
 	ss := st.(crdt.ISignatureStore)
-	ctx, cancel := context.WithCancel(context.Background())
 
-	return &documentStore{ctx, cancel, func() {}, addr, ui.userName, is, ss}, nil
+	indexDir := filepath.Join(baseDir, "index")
+	return newDocumentStore(addr, indexDir, func() {}, ui, is, ss), nil
 }
 
 func parseAddr(addr string) (string, string, error) {
@@ -109,15 +195,27 @@ func parseAddr(addr string) (string, string, error) {
 	return addrs[0], addrs[2], nil
 }
 
+func splitKey(key string) []string {
+	return strings.Split(strings.TrimPrefix(key, "/"), "/")
+}
+
+const anonymousName = "Anonymous"
+
+// an anonymous identity has a disposable key pair
+func AnonymousIdentity() *UserIdentity {
+	kp := NewKeyPair()
+	return &UserIdentity{anonymousName, kp.Verify(), kp.Sign()}
+}
+
 func parseUserIdentity(ui *UserIdentity) *UserIdentity {
 	if ui == nil {
-		return &UserIdentity{"Anonymous", nil, nil}
+		return AnonymousIdentity()
 	} else {
 		invalidName := ui.userName == ""
 		invalidVerf := ui.verfKey == nil
 		invalidSign := ui.signKey == nil
 		if invalidName || invalidVerf || invalidSign {
-			return &UserIdentity{"Anonymous", nil, nil}
+			return AnonymousIdentity()
 		}
 	}
 
@@ -133,21 +231,50 @@ func (ds *documentStore) Close() {
 	ds.dirCloser()
 }
 
-func (ds *documentStore) Ipfs() ipfs.Ipfs { return ds.is }
+func (ds *documentStore) Ipfs() ipfs.Ipfs { return trackedIpfs{ds.is, ds.pins} }
 
 func (ds *documentStore) SetUserIdentity(ui *UserIdentity) {
+	ds.keyMutex.Lock()
+	defer ds.keyMutex.Unlock()
 	ui = parseUserIdentity(ui)
-	ds.userName = ui.userName
+	ds.ui = ui
+	ds.ss.ResetKeyPair(ui.signKey, ui.verfKey)
+}
+
+// identity is the default user identity, set by SetUserIdentity.
+func (ds *documentStore) identity() *UserIdentity {
+	ds.keyMutex.Lock()
+	defer ds.keyMutex.Unlock()
+	return ds.ui
+}
+
+// put signs val by ui.
+// The key pair of ss is swapped for the put only, so all puts go through here.
+func (ds *documentStore) put(ui *UserIdentity, key string, val []byte) error {
+	ds.keyMutex.Lock()
+	defer ds.keyMutex.Unlock()
+	defer ds.ss.ResetKeyPair(ds.ui.signKey, ds.ui.verfKey)
 	ds.ss.ResetKeyPair(ui.signKey, ui.verfKey)
+	return ds.ss.Put(key, val)
+}
+func pid(ui *UserIdentity) string {
+	return crdt.PubKeyToStr(ui.verfKey)
 }
 func (ds *documentStore) Address() string { return ds.addr }
 
 func (ds *documentStore) Put(docName string, docInfo *DocumentInfo, data ...*TypedData) error {
+	return ds.PutAs(ds.identity(), docName, docInfo, data...)
+}
+
+// PutAs puts the document signed by ui instead of the default user identity.
+func (ds *documentStore) PutAs(ui *UserIdentity, docName string, docInfo *DocumentInfo, data ...*TypedData) error {
+	ui = parseUserIdentity(ui)
 	cids := make([]typedCid, 0)
 	for _, td := range data {
-		cid, err := ds.is.AddReader(td.data)
+		cr := &countReader{r: td.data}
+		cid, err := ds.Ipfs().AddReader(cr)
 		if err == nil {
-			cids = append(cids, typedCid{td.tp, cid})
+			cids = append(cids, typedCid{td.tp, cid, cr.n})
 		}
 	}
 	if len(cids) == 0 {
@@ -155,23 +282,45 @@ func (ds *documentStore) Put(docName string, docInfo *DocumentInfo, data ...*Typ
 	}
 
 	doc := newDocument(docInfo, cids...)
-	return ds.ss.Put(ds.userName+"/"+docName, doc.Marshal())
+	if err := ds.putRevision(ui, ui.userName+"/"+docName, doc); err != nil {
+		return err
+	}
+	// own documents are indexed at once
+	go ds.watcher.poll()
+	return nil
 }
 
+// Get returns the latest revision of the document.
 func (ds *documentStore) Get(key string) (*NamedDocument, error) {
-	m, err := ds.ss.Get(key)
+	if len(splitKey(key)) != 3 {
+		return nil, errors.New("invalid document key")
+	}
+	doc, _, err := ds.latestRevision(key)
 	if err != nil {
 		return nil, err
 	}
-	doc := newEmptyDocument()
-	if err := doc.Unmarshal(m); err != nil {
+
+	return &NamedDocument{doc, key, ds.retraction(key), ds.signer(revisionKey(key, doc.Revision))}, nil
+}
+
+func (ds *documentStore) Query(qs ...query.Query) (<-chan *NamedDocument, error) {
+	ndocs, err := ds.queryDocuments(qs...)
+	if err != nil {
 		return nil, err
 	}
 
-	return &NamedDocument{doc, key}, nil
+	ch := make(chan *NamedDocument, 10)
+	go func() {
+		defer close(ch)
+		for _, ndoc := range ndocs {
+			ds.setSigner(ndoc)
+			ch <- ndoc
+		}
+	}()
+	return ch, nil
 }
 
-func (ds *documentStore) Query(qs ...query.Query) (<-chan *NamedDocument, error) {
+func (ds *documentStore) queryDocuments(qs ...query.Query) ([]*NamedDocument, error) {
 	var q query.Query
 	if len(qs) > 0 {
 		q = qs[0]
@@ -180,36 +329,65 @@ func (ds *documentStore) Query(qs ...query.Query) (<-chan *NamedDocument, error)
 			Orders: []query.Order{TimeOrder{true}},
 		}
 	}
-	q.Filters = append([]query.Filter{documentFilter{}}, q.Filters...)
+	fs, includeRetracted := withoutIncludeRetracted(q.Filters)
+	q.Filters = append([]query.Filter{documentFilter{}}, fs...)
 	if q.KeysOnly {
 		q.KeysOnly = false
 	}
 
-	rs, err := ds.ss.Query()
+	es, retractions, err := ds.queryEntries(fs, includeRetracted)
 	if err != nil {
 		return nil, err
 	}
-	rs = query.NaiveQueryApply(q, rs)
+	des := applyDocumentQuery(q, es)
 
-	ch := make(chan *NamedDocument, 10)
-	go func() {
-		defer close(ch)
-		for res := range rs.Next() {
-			doc := newEmptyDocument()
-			if err := doc.Unmarshal(res.Value); err != nil {
-				continue
-			}
-			ch <- &NamedDocument{doc, res.Key}
+	ndocs := make([]*NamedDocument, len(des))
+	for idx, de := range des {
+		key := strings.TrimPrefix(de.Key, "/")
+		r := retractions[key]
+		ndocs[idx] = &NamedDocument{de.Document, de.Key, r, nil}
+	}
+	return ndocs, nil
+}
+
+// setSigner looks up the signer of a document returned by queryDocuments.
+// It reads the signed entry, so it is done only for the documents handed to the caller.
+func (ds *documentStore) setSigner(ndoc *NamedDocument) {
+	key := strings.TrimPrefix(ndoc.Name, "/")
+	ndoc.Signer = ds.signer(revisionKey(key, ndoc.Revision))
+}
+
+// queryEntries returns the latest revision of the documents which may match fs.
+// Filters on tags, doc types, authors and time are served from the local index
+// once it has caught up with the store. Until then all entries are scanned.
+func (ds *documentStore) queryEntries(fs []query.Filter, includeRetracted bool) ([]query.Entry, map[string]*Retraction, error) {
+	if revs, ok := ds.docIndex.candidates(fs); ok && !ds.watcher.behind() {
+		if es, retractions, ok := ds.indexedEntries(revs, includeRetracted); ok {
+			return es, retractions, nil
 		}
-	}()
-	return ch, nil
+	}
+
+	rs, err := ds.ss.Query()
+	if err != nil {
+		return nil, nil, err
+	}
+	es, err := rs.Rest()
+	if err != nil {
+		return nil, nil, err
+	}
+	retractions := retractionsFromEntries(es)
+	es = latestRevisionEntries(es)
+	if !includeRetracted {
+		es = withoutRetracted(es, retractions)
+	}
+	return es, retractions, nil
 }
 
 type documentFilter struct{}
 
 func (f documentFilter) Filter(e query.Entry) bool {
 	// e.Key: pid/username/docname
-	keys := strings.Split(strings.TrimPrefix(e.Key, "/"), "/")
+	keys := splitKey(e.Key)
 	if len(keys) != 3 {
 		return false
 	}
@@ -218,3 +396,6 @@ func (f documentFilter) Filter(e query.Entry) bool {
 	err := d.Unmarshal(e.Value)
 	return err == nil
 }
+func (f documentFilter) FilterDocument(d *DocumentEntry) bool {
+	return len(splitKey(d.Key)) == 3
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	pv "github.com/pilinsin/p2p-verse"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+)
+
+// newTestDocumentStore makes a document store on local hosts, with its indexes in memory.
+// Stores of different names do not sync with each other.
+func newTestDocumentStore(t *testing.T, b pv.IBootstrap, name string) *documentStore {
+	dir := t.TempDir()
+	ui := AnonymousIdentity()
+	is, err := ipfs.NewIpfsStore(pv.SampleHost, filepath.Join(dir, "ipfs"), false, b.AddrInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := crdt.NewVerse(pv.SampleHost, filepath.Join(dir, "store"), false, b.AddrInfo())
+	st, err := v.NewStore(name, "signature", &crdt.StoreOpts{Pub: ui.verfKey, Priv: ui.signKey})
+	if err != nil {
+		is.Close()
+		t.Fatal(err)
+	}
+
+	ds := newDocumentStore("bootstrap/test/"+name, "", func() {}, ui, is, st.(crdt.ISignatureStore))
+	t.Cleanup(ds.Close)
+	return ds
+}
+
+func newTestIdentity(name string) *UserIdentity {
+	kp := NewKeyPair()
+	return NewUserIdentity(name, kp.Verify(), kp.Sign())
+}
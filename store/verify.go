@@ -0,0 +1,159 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+type VerifyIssue struct {
+	// the entry key, or the cid of the data
+	Item string
+	// the document entry which references the data ("" for an entry)
+	Key string
+	// the data could not be retrieved, it needs re-seeding
+	Missing bool
+	Reason  string
+}
+
+func (vi VerifyIssue) String() string {
+	if vi.Key == "" {
+		return fmt.Sprintf("%s: %s", vi.Item, vi.Reason)
+	}
+	return fmt.Sprintf("%s (in %s): %s", vi.Item, vi.Key, vi.Reason)
+}
+
+type VerifyReport struct {
+	Entries int
+	Data    int
+	Issues  []VerifyIssue
+}
+
+func (r *VerifyReport) add(item, key string, missing bool, err error) {
+	r.Issues = append(r.Issues, VerifyIssue{item, key, missing, err.Error()})
+}
+
+// Missing returns the cids which could not be retrieved.
+func (r *VerifyReport) Missing() []string {
+	cids := make(map[string]struct{})
+	for _, vi := range r.Issues {
+		if vi.Missing {
+			cids[vi.Item] = struct{}{}
+		}
+	}
+	missing := mapToSlice(cids)
+	sort.Strings(missing)
+	return missing
+}
+
+// checkData checks that m decodes as the data of the type.
+func checkData(tp string, m []byte) error {
+	switch tp {
+	case "text":
+		if !utf8.Valid(m) {
+			return errors.New("invalid utf-8 text")
+		}
+	case "image":
+		img := &pb.Image{}
+		if err := proto.Unmarshal(m, img); err != nil {
+			return err
+		}
+		if len(img.GetData()) == 0 {
+			return errors.New("empty image")
+		}
+	case "pdf":
+		pdf := &pb.Pdf{}
+		if err := proto.Unmarshal(m, pdf); err != nil {
+			return err
+		}
+		if len(pdf.GetImages()) == 0 {
+			return errors.New("empty pdf")
+		}
+	case "video":
+		video := &pb.Video{}
+		if err := proto.Unmarshal(m, video); err != nil {
+			return err
+		}
+		if len(video.GetVideo()) == 0 {
+			return errors.New("empty video")
+		}
+	case "audio":
+		audio := &pb.Audio{}
+		if err := proto.Unmarshal(m, audio); err != nil {
+			return err
+		}
+		if len(audio.GetData()) == 0 {
+			return errors.New("empty audio")
+		}
+	case "document":
+		// the previous revision
+		return newEmptyDocument().Unmarshal(m)
+	default:
+		return errors.New("unknown data type " + tp)
+	}
+	return nil
+}
+
+// verifyData returns whether the data is retrievable, and the decode error.
+func (ds *documentStore) verifyData(tp, cid string) (bool, error) {
+	r, err := ds.is.GetReader(cid, archiveFetchTimeout)
+	if err != nil {
+		return false, err
+	}
+	m, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	return true, checkData(tp, m)
+}
+
+// Verify checks every entry of the store:
+// signatures are valid, documents and revisions decode,
+// and the data they reference is retrievable and decodes as its type.
+// Missing data is fetched from the network with a timeout for each cid.
+func (ds *documentStore) Verify() (*VerifyReport, error) {
+	ses, err := ds.signedEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	checked := make(map[string]struct{})
+	for _, se := range ses {
+		report.Entries++
+		if !se.Verify() {
+			report.add(se.Key, "", false, errors.New("invalid signature"))
+		}
+		if _, _, ok := revisionDocKey(se.Key); !ok && len(splitKey(se.Key)) != 3 {
+			continue
+		}
+		doc := newEmptyDocument()
+		if err := doc.Unmarshal(se.Value); err != nil {
+			report.add(se.Key, "", false, err)
+			continue
+		}
+
+		tcs := doc.Cids
+		if doc.Prev != "" {
+			tcs = append(tcs, typedCid{"document", doc.Prev, 0})
+		}
+		for _, tc := range tcs {
+			if _, ok := checked[tc.Cid]; ok {
+				continue
+			}
+			checked[tc.Cid] = struct{}{}
+			report.Data++
+			found, err := ds.verifyData(tc.Type, tc.Cid)
+			if err != nil {
+				report.add(tc.Cid, se.Key, !found, err)
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	proto "google.golang.org/protobuf/proto"
+
+	pb "github.com/pilinsin/lontan/store/pb"
+)
+
+func marshalTest(t *testing.T, m proto.Message) []byte {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCheckData(t *testing.T) {
+	info := NewDocumentInfo("title", "description", nil, nil, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name  string
+		tp    string
+		m     []byte
+		valid bool
+	}{
+		{"text", "text", []byte("text"), true},
+		{"invalid utf-8", "text", []byte{0xff, 0xfe}, false},
+		{"image", "image", marshalTest(t, &pb.Image{Data: []byte{1}}), true},
+		{"empty image", "image", marshalTest(t, &pb.Image{}), false},
+		{"pdf", "pdf", marshalTest(t, &pb.Pdf{Images: [][]byte{{1}}}), true},
+		{"empty pdf", "pdf", marshalTest(t, &pb.Pdf{}), false},
+		{"video", "video", marshalTest(t, &pb.Video{Video: []byte{1}}), true},
+		{"empty video", "video", marshalTest(t, &pb.Video{Audio: []byte{1}}), false},
+		{"audio", "audio", marshalTest(t, &pb.Audio{Data: []byte{1}}), true},
+		{"empty audio", "audio", marshalTest(t, &pb.Audio{}), false},
+		{"broken", "image", []byte{0xff}, false},
+		{"previous revision", "document", newDocument(info, typedCid{"text", "cid", 4}).Marshal(), true},
+		{"unknown type", "model", []byte{1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkData(tt.tp, tt.m)
+			if tt.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ds := newTestDocumentStore(t, newTestBootstrap(t), "verify")
+	ui := newTestIdentity("alice")
+	putTestDocument(t, ds, ui, "doc", "first", "first text")
+	putTestDocument(t, ds, ui, "doc", "second", "second text")
+	info := NewDocumentInfo("broken", "description", nil, nil, time.Now())
+	if err := ds.PutAs(ui, "broken", info, NewTypedData("text", strings.NewReader("\xff\xfe"))); err != nil {
+		t.Fatal(err)
+	}
+	broken, err := ds.Get(pid(ui) + "/alice/broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ds.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two revisions of doc, with the previous one as data, and broken
+	if report.Entries != 3 || report.Data != 4 {
+		t.Errorf("checked %d entries and %d data, want 3 and 4", report.Entries, report.Data)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Item != broken.Cids[0].Cid || report.Issues[0].Missing {
+		t.Errorf("issues %v, want the data of broken", report.Issues)
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	query "github.com/ipfs/go-datastore/query"
+	proto "google.golang.org/protobuf/proto"
+
+	ipfslt "github.com/hsanjuan/ipfs-lite"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+	crdtpb "github.com/pilinsin/p2p-verse/crdt/pb"
+	ipfs "github.com/pilinsin/p2p-verse/ipfs"
+)
+
+// p2p-verse up to v0.0.0-20220719115403-0577f7062b5d exports neither the signed entries
+// of the signature store nor the blockstore of the ipfs store.
+// The stores of later releases are used through rawSignatureStore and blockStorer,
+// and the stores of the pinned release through their unexported fields.
+
+// unexportedField returns the field of the struct which v points to.
+func unexportedField(v interface{}, name string) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	f := rv.Elem().FieldByName(name)
+	if !f.IsValid() || !f.CanAddr() {
+		return reflect.Value{}, false
+	}
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem(), true
+}
+
+// baseStore is the store embedded in the signature store. Its values are marshaled crdtpb.SignatureData.
+type baseStore interface {
+	Get(string) ([]byte, error)
+	Query(...query.Query) (query.Results, error)
+	Put(string, []byte) error
+}
+
+type embeddedRawStore struct {
+	bs baseStore
+}
+
+func newEmbeddedRawStore(ss crdt.ISignatureStore) (*embeddedRawStore, error) {
+	f, ok := unexportedField(ss, "baseStore")
+	if !ok || f.Kind() != reflect.Ptr || f.IsNil() {
+		return nil, errors.New("unsupported signature store")
+	}
+	bs, ok := f.Interface().(baseStore)
+	if !ok {
+		return nil, errors.New("unsupported signature store")
+	}
+	return &embeddedRawStore{bs}, nil
+}
+
+func (s *embeddedRawStore) GetRaw(key string) ([]byte, error) {
+	return s.bs.Get(key)
+}
+func (s *embeddedRawStore) QueryRaw(qs ...query.Query) (query.Results, error) {
+	return s.bs.Query(qs...)
+}
+
+// PutPresigned puts the value with the signature of its author, as the signature store puts its own.
+func (s *embeddedRawStore) PutPresigned(key string, val, sign []byte) error {
+	key = strings.TrimPrefix(key, "/")
+	if !(&SignedEntry{key, val, sign}).Verify() {
+		return errors.New("invalid signature")
+	}
+	msd, err := proto.Marshal(&crdtpb.SignatureData{Value: val, Sign: sign})
+	if err != nil {
+		return err
+	}
+	return s.bs.Put(key, msd)
+}
+
+// embeddedBlockStore returns the blockstore of the ipfs-lite peer in the ipfs store.
+func embeddedBlockStore(is ipfs.Ipfs) (blockstore.Blockstore, error) {
+	f, ok := unexportedField(is, "ipfs")
+	if !ok || f.Type() != reflect.TypeOf(&ipfslt.Peer{}) || f.IsNil() {
+		return nil, errors.New("unsupported ipfs store")
+	}
+	return f.Interface().(*ipfslt.Peer).BlockStore(), nil
+}
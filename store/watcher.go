@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	query "github.com/ipfs/go-datastore/query"
+
+	crdt "github.com/pilinsin/p2p-verse/crdt"
+)
+
+const watchInterval = time.Second * 10
+
+// entries in the signature store are never overwritten,
+// so each key is passed to indexers only once.
+type entryIndexer interface {
+	put(query.Entry)
+	flush() error
+}
+
+// retryingIndexer is an entryIndexer which retries the entries it failed to index at each poll.
+type retryingIndexer interface {
+	entryIndexer
+	retry() bool
+}
+
+// mutex guards the fields, and is not held while indexers run
+// since they may fetch data from the network. indexing runs polls one at a time.
+type storeWatcher struct {
+	mutex    sync.Mutex
+	indexing sync.Mutex
+	ss       crdt.ISignatureStore
+	seen     map[string]struct{}
+	indexers []entryIndexer
+	// caughtUp is true once a poll has passed all entries to all indexers
+	caughtUp bool
+	// incremented by subscribe and replay
+	gen int
+}
+
+func newStoreWatcher(ss crdt.ISignatureStore) *storeWatcher {
+	return &storeWatcher{
+		ss:       ss,
+		seen:     make(map[string]struct{}),
+		indexers: make([]entryIndexer, 0),
+	}
+}
+
+// all entries are passed again at the next poll so that idx catches up.
+// Indexers must ignore entries they already have.
+func (w *storeWatcher) subscribe(idx entryIndexer) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.indexers = append(w.indexers, idx)
+	w.seen = make(map[string]struct{})
+	w.caughtUp = false
+	w.gen++
+}
+
+// replay passes all entries again at the next poll.
+func (w *storeWatcher) replay() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.seen = make(map[string]struct{})
+	w.caughtUp = false
+	w.gen++
+}
+
+// behind returns true if the indexers may miss some entries of the store:
+// until the first poll after subscribe or replay, and while entries which arrived
+// since the last poll wait for the next one.
+func (w *storeWatcher) behind() bool {
+	rs, err := w.ss.Query(query.Query{KeysOnly: true})
+	if err != nil {
+		return true
+	}
+	defer rs.Close()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.caughtUp {
+		return true
+	}
+	for res := range rs.Next() {
+		if res.Error != nil {
+			continue
+		}
+		if _, ok := w.seen[res.Key]; !ok {
+			go w.poll()
+			return true
+		}
+	}
+	return false
+}
+
+// collect returns the entries which arrived since the last poll, and marks them seen.
+func (w *storeWatcher) collect() ([]query.Entry, []entryIndexer, int, error) {
+	rs, err := w.ss.Query()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer rs.Close()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	es := make([]query.Entry, 0)
+	for res := range rs.Next() {
+		if res.Error != nil {
+			continue
+		}
+		if _, ok := w.seen[res.Key]; ok {
+			continue
+		}
+		w.seen[res.Key] = struct{}{}
+		es = append(es, res.Entry)
+	}
+	if len(es) > 0 {
+		w.caughtUp = false
+	}
+	indexers := append([]entryIndexer{}, w.indexers...)
+	return es, indexers, w.gen, nil
+}
+
+// poll passes the entries which arrived since the last poll to the indexers.
+func (w *storeWatcher) poll() error {
+	w.indexing.Lock()
+	defer w.indexing.Unlock()
+
+	es, indexers, gen, err := w.collect()
+	if err != nil {
+		return err
+	}
+	for _, e := range es {
+		for _, idx := range indexers {
+			idx.put(e)
+		}
+	}
+	var flushErr error
+	for _, idx := range indexers {
+		retried := false
+		if r, ok := idx.(retryingIndexer); ok {
+			retried = r.retry()
+		}
+		if len(es) == 0 && !retried {
+			continue
+		}
+		if err := idx.flush(); err != nil {
+			flushErr = err
+		}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	// entries are passed again after a replay during the poll
+	if w.gen == gen {
+		w.caughtUp = true
+	}
+	return flushErr
+}
+
+func (w *storeWatcher) run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			w.poll()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}